@@ -126,13 +126,24 @@ func (v BoostHash) Bytes() []byte {
 
 type BoostBlockIds []string
 
+// Bytes сериализует список, дописывая genesis блок mainnet
 func (blockIds BoostBlockIds) Bytes() []byte {
-	if len(blockIds) == 0 {
+	return BoostChainBlockIds{Ids: blockIds, Genesis: MainnetGenesisTxByte}.Bytes()
+}
+
+// BoostChainBlockIds - список block_ids, оканчивающийся genesis блоком заданной сети
+type BoostChainBlockIds struct {
+	Ids     []string
+	Genesis []byte
+}
+
+func (blockIds BoostChainBlockIds) Bytes() []byte {
+	if len(blockIds.Ids) == 0 {
 		return []byte{}
 	}
 
-	temp := make([]byte, 0, len(blockIds)*HASH_SIZE)
-	for _, blockId := range blockIds {
+	temp := make([]byte, 0, len(blockIds.Ids)*HASH_SIZE)
+	for _, blockId := range blockIds.Ids {
 		hashBytes, err := hex.DecodeString(blockId)
 		if err != nil || len(hashBytes) != HASH_SIZE {
 			return nil
@@ -141,7 +152,7 @@ func (blockIds BoostBlockIds) Bytes() []byte {
 		temp = append(temp, hashBytes...)
 	}
 
-	payloadSize := len(MainnetGenesisTxByte) + len(temp)
+	payloadSize := len(blockIds.Genesis) + len(temp)
 	varInB, err := VarIn(payloadSize)
 	if err != nil || len(varInB) != 2 {
 		return nil
@@ -156,7 +167,7 @@ func (blockIds BoostBlockIds) Bytes() []byte {
 	result := make([]byte, 0, payloadSize+3)
 	result = append(result, prefix...)
 	result = append(result, temp...)
-	result = append(result, MainnetGenesisTxByte...)
+	result = append(result, blockIds.Genesis...)
 
 	return result
 }
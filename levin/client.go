@@ -9,16 +9,21 @@ import (
 	"net"
 	"slices"
 	"time"
+
+	"github.com/0xAF4/go-monero/util"
 )
 
 const DialTimeout = 15 * time.Second
 
 type Client struct {
-	conn net.Conn
+	conn    net.Conn
+	network *util.NetworkParams
 }
 
 type ClientConfig struct {
 	ContextDialer ContextDialer
+	// Network - сеть узла, по умолчанию mainnet
+	Network *util.NetworkParams
 }
 
 type ClientOption func(*ClientConfig)
@@ -33,9 +38,18 @@ func WithContextDialer(v ContextDialer) func(*ClientConfig) {
 	}
 }
 
+// WithNetwork задаёт сеть: network_id, порт и версию протокола в handshake,
+// genesis блок в NOTIFY_REQUEST_CHAIN
+func WithNetwork(v *util.NetworkParams) func(*ClientConfig) {
+	return func(c *ClientConfig) {
+		c.Network = v
+	}
+}
+
 func NewClient(addr string, opts ...ClientOption) (*Client, error) {
 	cfg := &ClientConfig{
 		ContextDialer: &net.Dialer{},
+		Network:       util.MainnetParams,
 	}
 	for _, opt := range opts {
 		opt(cfg)
@@ -47,10 +61,22 @@ func NewClient(addr string, opts ...ClientOption) (*Client, error) {
 	}
 
 	return &Client{
-		conn: conn,
+		conn:    conn,
+		network: cfg.Network,
 	}, nil
 }
 
+// Network возвращает параметры сети клиента
+func (c *Client) Network() *util.NetworkParams {
+	return c.network
+}
+
+// ChainBlockIds возвращает список block_ids для NOTIFY_REQUEST_CHAIN,
+// оканчивающийся genesis блоком сети клиента
+func (c *Client) ChainBlockIds(ids []string) BoostChainBlockIds {
+	return BoostChainBlockIds{Ids: ids, Genesis: c.network.GenesisHashBytes()}
+}
+
 func (c *Client) Close() error {
 	if c.conn == nil {
 		return nil
@@ -63,7 +89,25 @@ func (c *Client) Close() error {
 	return nil
 }
 
-func (c *Client) Handshake(Height uint64, Hash string, peer_id uint64) (*Node, error) {
+// NewCoreSyncData собирает данные вершины для handshake и timed sync: height - высота
+// цепочки (вершина + 1), topId и cumulative difficulty - вершины. top_version берётся
+// из хардфорков сети клиента для блока height-1, как сверяет monerod.
+func (c *Client) NewCoreSyncData(height uint64, topId string, cumulativeDifficulty, cumulativeDifficultyTop64 uint64) CoreSyncData {
+	topHeight := height
+	if topHeight > 0 {
+		topHeight--
+	}
+
+	return CoreSyncData{
+		CurrentHeight:             height,
+		CumulativeDifficulty:      cumulativeDifficulty,
+		CumulativeDifficultyTop64: cumulativeDifficultyTop64,
+		TopId:                     topId,
+		TopVersion:                c.network.HardForkVersion(topHeight),
+	}
+}
+
+func (c *Client) Handshake(data CoreSyncData, peer_id uint64) (*Node, error) {
 	payload := (&PortableStorage{
 		Entries: []Entry{
 			{
@@ -72,11 +116,11 @@ func (c *Client) Handshake(Height uint64, Hash string, peer_id uint64) (*Node, e
 					Entries: []Entry{
 						{
 							Name:         "network_id",
-							Serializable: BoostString(string(MainnetNetworkId)),
+							Serializable: BoostString(string(c.network.NetworkID[:])),
 						},
 						{
 							Name:         "my_port",
-							Serializable: BoostUint32(MyPort),
+							Serializable: BoostUint32(uint32(c.network.P2PPort)),
 						},
 						{
 							Name:         "peer_id",
@@ -90,31 +134,8 @@ func (c *Client) Handshake(Height uint64, Hash string, peer_id uint64) (*Node, e
 				},
 			},
 			{
-				Name: "payload_data",
-				Serializable: &Section{
-					Entries: []Entry{
-						{
-							Name:         "cumulative_difficulty",
-							Serializable: BoostUint64(CumulativeDifficulty),
-						},
-						{
-							Name:         "cumulative_difficulty_top64",
-							Serializable: BoostUint64(CumulativeDifficultyTop64),
-						},
-						{
-							Name:         "current_height",
-							Serializable: BoostUint64(Height),
-						},
-						{
-							Name:         "top_id",
-							Serializable: BoostHash(Hash),
-						},
-						{
-							Name:         "top_version",
-							Serializable: BoostUint8(TopVersion),
-						},
-					},
-				},
+				Name:         "payload_data",
+				Serializable: data.section(),
 			},
 		},
 	}).Bytes()
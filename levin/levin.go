@@ -62,12 +62,9 @@ const (
 
 const (
 	/*--- OTHER CONSTANTS ---*/
-	TopVersion                uint8  = 16
-	CumulativeDifficultyTop64 uint64 = 0
-	CumulativeDifficulty      uint64 = 1559285794759
-	SupportFlags              uint32 = 1
-	MyPort                    uint32 = 18080
-	HASH_SIZE                        = 32
+	SupportFlags uint32 = 1
+	MyPort       uint32 = 18080
+	HASH_SIZE           = 32
 )
 
 var (
@@ -32,44 +32,45 @@ type ResponseTimedSync struct {
 	LocalPeerlistNewE Entry
 }
 
-func NewRequestTimedSync(Height uint64, Hash string) *RequestTimedSync {
-	return &RequestTimedSync{
-		PayloadData: CoreSyncData{
-			CurrentHeight: Height,
-			TopId:         Hash,
+// section - payload_data для handshake и timed sync
+func (d CoreSyncData) section() *Section {
+	return &Section{
+		Entries: []Entry{
+			{
+				Name:         "cumulative_difficulty",
+				Serializable: BoostUint64(d.CumulativeDifficulty),
+			},
+			{
+				Name:         "cumulative_difficulty_top64",
+				Serializable: BoostUint64(d.CumulativeDifficultyTop64),
+			},
+			{
+				Name:         "current_height",
+				Serializable: BoostUint64(d.CurrentHeight),
+			},
+			{
+				Name:         "top_id",
+				Serializable: BoostHash(d.TopId),
+			},
+			{
+				Name:         "top_version",
+				Serializable: BoostUint8(d.TopVersion),
+			},
 		},
 	}
 }
 
+// NewRequestTimedSync создаёт запрос; data обычно собирается Client.NewCoreSyncData
+func NewRequestTimedSync(data CoreSyncData) *RequestTimedSync {
+	return &RequestTimedSync{PayloadData: data}
+}
+
 func (r *RequestTimedSync) Bytes() []byte {
 	return (&PortableStorage{
 		Entries: []Entry{
 			{
-				Name: "payload_data",
-				Serializable: &Section{
-					Entries: []Entry{
-						{
-							Name:         "cumulative_difficulty",
-							Serializable: BoostUint64(CumulativeDifficulty),
-						},
-						{
-							Name:         "cumulative_difficulty_top64",
-							Serializable: BoostUint64(CumulativeDifficultyTop64),
-						},
-						{
-							Name:         "current_height",
-							Serializable: BoostUint64(r.PayloadData.CurrentHeight),
-						},
-						{
-							Name:         "top_id",
-							Serializable: BoostHash(r.PayloadData.TopId),
-						},
-						{
-							Name:         "top_version",
-							Serializable: BoostUint8(TopVersion),
-						},
-					},
-				},
+				Name:         "payload_data",
+				Serializable: r.PayloadData.section(),
 			},
 		},
 	}).Bytes()
@@ -0,0 +1,221 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/0xAF4/go-monero/levin"
+	"github.com/0xAF4/go-monero/types"
+)
+
+// GetBlocksFast вызывает /get_blocks.bin: блоки после последнего общего блока из
+// req.BlockIds (или с req.StartHeight), глобальные индексы их выходов и, по запросу, изменения пула
+func (c *Client) GetBlocksFast(req types.BlocksRequest) (*types.BlocksResult, error) {
+	return c.GetBlocksFastCtx(context.Background(), req)
+}
+
+func (c *Client) GetBlocksFastCtx(ctx context.Context, req types.BlocksRequest) (*types.BlocksResult, error) {
+	blockIds := make([]byte, 0, len(req.BlockIds)*levin.HASH_SIZE)
+	for _, id := range req.BlockIds {
+		blockIds = append(blockIds, id[:]...)
+	}
+
+	request := UniversalRequest{
+		"requested_info":  uint8(req.RequestedInfo),
+		"block_ids":       string(blockIds),
+		"start_height":    req.StartHeight,
+		"prune":           req.Prune,
+		"no_miner_tx":     req.NoMinerTx,
+		"pool_info_since": req.PoolInfoSince,
+	}
+
+	response, err := c.cycleCall(ctx, cGetBlocksFast, request.MarshalToBlob())
+	if err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetBlocksFast, err)
+	}
+
+	resp := make(UniversalRequest)
+	if err := resp.FromPortableStorate(response); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 2, cGetBlocksFast, err)
+	}
+
+	if status, _ := resp["status"].(string); strings.ToLower(status) != "ok" {
+		return nil, fmt.Errorf("error, request is not ok: %q", status)
+	}
+
+	result := &types.BlocksResult{}
+	result.StartHeight, _ = resp["start_height"].(uint64)
+	result.CurrentHeight, _ = resp["current_height"].(uint64)
+	result.DaemonTime, _ = resp["daemon_time"].(uint64)
+	if extent, ok := resp["pool_info_extent"].(uint8); ok {
+		result.PoolInfoExtent = types.PoolInfoExtent(extent)
+	}
+	if top, ok := resp["top_block_hash"].(string); ok && len(top) == levin.HASH_SIZE {
+		result.TopBlockHash = types.Hash([]byte(top))
+	}
+
+	blocks, _ := resp["blocks"].(levin.Entries)
+	indices, _ := resp["output_indices"].(levin.Entries)
+	if len(indices) != 0 && len(indices) != len(blocks) {
+		return nil, fmt.Errorf("output_indices count mismatch: got %d, expected %d", len(indices), len(blocks))
+	}
+
+	for i, blk := range blocks {
+		entry := types.BlockEntry{
+			Block:  types.NewBlock(),
+			Height: result.StartHeight + uint64(i),
+		}
+
+		fields, ok := blk.Value.(levin.Entries)
+		if !ok {
+			return nil, fmt.Errorf("block %d: unexpected entry type %T", i, blk.Value)
+		}
+		for _, f := range fields {
+			switch f.Name {
+			case "block":
+				entry.Block.SetBlockData([]byte(f.String()))
+			case "txs":
+				txs, _ := f.Value.(levin.Entries)
+				for _, tx := range txs {
+					blob, prunableHash, err := readTxBlobEntry(tx)
+					if err != nil {
+						return nil, fmt.Errorf("block %d: %w", i, err)
+					}
+					entry.Block.InsertTx(blob)
+					if prunableHash != nil {
+						entry.PrunableHashes = append(entry.PrunableHashes, *prunableHash)
+					}
+				}
+			}
+		}
+
+		if err := entry.Block.FullfillBlockHeader(); err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, err)
+		}
+
+		if len(indices) != 0 {
+			if entry.OutputIndices, err = readBlockOutputIndices(indices[i]); err != nil {
+				return nil, fmt.Errorf("block %d: %w", i, err)
+			}
+		}
+
+		result.Blocks = append(result.Blocks, entry)
+	}
+
+	if added, ok := resp["added_pool_txs"].(levin.Entries); ok {
+		for _, tx := range added {
+			fields, _ := tx.Value.(levin.Entries)
+			info := types.PoolTxInfo{}
+			for _, f := range fields {
+				switch f.Name {
+				case "tx_hash":
+					if hash, ok := f.Value.(string); ok && len(hash) == levin.HASH_SIZE {
+						info.Hash = types.Hash([]byte(hash))
+					}
+				case "tx_blob":
+					info.Blob = []byte(f.String())
+				case "double_spend_seen":
+					info.DoubleSpendSeen, _ = f.Value.(bool)
+				}
+			}
+			result.AddedPoolTxs = append(result.AddedPoolTxs, info)
+		}
+	}
+
+	if result.RemainingAddedPoolTxids, err = readHashBlob(resp["remaining_added_pool_txids"]); err != nil {
+		return nil, fmt.Errorf("remaining_added_pool_txids: %w", err)
+	}
+	if result.RemovedPoolTxids, err = readHashBlob(resp["removed_pool_txids"]); err != nil {
+		return nil, fmt.Errorf("removed_pool_txids: %w", err)
+	}
+
+	return result, nil
+}
+
+// readTxBlobEntry читает транзакцию блока: строку или, для pruned блоков,
+// объект {blob, prunable_hash}
+func readTxBlobEntry(tx levin.Entry) ([]byte, *types.Hash, error) {
+	switch v := tx.Value.(type) {
+	case string:
+		return []byte(v), nil, nil
+	case levin.Entries:
+		var (
+			blob         []byte
+			prunableHash types.Hash
+		)
+		for _, f := range v {
+			switch f.Name {
+			case "blob":
+				blob = []byte(f.String())
+			case "prunable_hash":
+				if hash, ok := f.Value.(string); ok && len(hash) == levin.HASH_SIZE {
+					prunableHash = types.Hash([]byte(hash))
+				}
+			}
+		}
+		return blob, &prunableHash, nil
+	default:
+		return nil, nil, fmt.Errorf("unexpected tx entry type %T", tx.Value)
+	}
+}
+
+// readBlockOutputIndices читает {indices: [{indices: [uint64...]}...]}
+func readBlockOutputIndices(block levin.Entry) ([][]uint64, error) {
+	fields, ok := block.Value.(levin.Entries)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output indices type %T", block.Value)
+	}
+
+	result := [][]uint64{}
+	for _, f := range fields {
+		if f.Name != "indices" {
+			continue
+		}
+		txs, _ := f.Value.(levin.Entries)
+		for _, tx := range txs {
+			txFields, _ := tx.Value.(levin.Entries)
+			txIndices := []uint64{}
+			for _, tf := range txFields {
+				if tf.Name != "indices" {
+					continue
+				}
+				values, _ := tf.Value.(levin.Entries)
+				for _, v := range values {
+					idx, ok := v.Value.(uint64)
+					if !ok {
+						return nil, fmt.Errorf("unexpected output index type %T", v.Value)
+					}
+					txIndices = append(txIndices, idx)
+				}
+			}
+			result = append(result, txIndices)
+		}
+	}
+	return result, nil
+}
+
+// readHashBlob разбирает склеенные 32-байтные хеши; отсутствующее поле - пустой список
+func readHashBlob(v interface{}) ([]types.Hash, error) {
+	var raw []byte
+	switch b := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		raw = []byte(b)
+	case []byte:
+		raw = b
+	default:
+		return nil, fmt.Errorf("unexpected type %T", v)
+	}
+
+	if len(raw)%levin.HASH_SIZE != 0 {
+		return nil, fmt.Errorf("invalid length: %d", len(raw))
+	}
+
+	hashes := make([]types.Hash, 0, len(raw)/levin.HASH_SIZE)
+	for i := 0; i < len(raw); i += levin.HASH_SIZE {
+		hashes = append(hashes, types.Hash(raw[i:i+levin.HASH_SIZE]))
+	}
+	return hashes, nil
+}
@@ -1,38 +1,77 @@
 package rpc
 
 import (
+	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"slices"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/0xAF4/go-monero/levin"
 	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
 )
 
 type Client struct {
 	timeout      time.Duration
 	retriesCount int
 	hostList     *[]string
+	network      *util.NetworkParams
+	// httpClient - общий для всех запросов, переиспользует соединения
+	httpClient *http.Client
 }
 
 func NewDaemonRPCClient(tout time.Duration, retriesCount int, hostList *[]string) *Client {
+	return NewNetworkDaemonRPCClient(util.MainnetParams, tout, retriesCount, hostList)
+}
+
+// NewNetworkDaemonRPCClient создаёт клиент для демона сети network.
+// Публичные узлы используются только для mainnet; для остальных сетей без hostList
+// запросы идут на локальный демон с портом RPC сети по умолчанию.
+func NewNetworkDaemonRPCClient(network *util.NetworkParams, tout time.Duration, retriesCount int, hostList *[]string) *Client {
 	return &Client{
 		timeout:      tout,
 		retriesCount: retriesCount,
 		hostList:     hostList,
+		network:      network,
+		httpClient:   newHTTPClient(tout),
+	}
+}
+
+func newHTTPClient(tout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cMaxIdleConnsPerHost
+
+	return &http.Client{
+		Timeout:   tout,
+		Transport: transport,
 	}
 }
 
+// Close закрывает простаивающие соединения пула
+func (c *Client) Close() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// Network возвращает параметры сети клиента
+func (c *Client) Network() *util.NetworkParams {
+	return c.network
+}
+
 func (c *Client) GetBlocks(heights []uint64) ([]*types.Block, error) {
+	return c.GetBlocksCtx(context.Background(), heights)
+}
+
+func (c *Client) GetBlocksCtx(ctx context.Context, heights []uint64) ([]*types.Block, error) {
 	req := UniversalRequest{
 		"heights": heights,
 	}
 
 	// Для /get_blocks_by_height.bin используем JSON в запросе
-	response, err := c.cycleCall(cGetBlocks, req.MarshalToBlob())
+	response, err := c.cycleCall(ctx, cGetBlocks, req.MarshalToBlob())
 	if err != nil {
 		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetBlocks, err)
 	}
@@ -69,52 +108,61 @@ func (c *Client) GetBlocks(heights []uint64) ([]*types.Block, error) {
 	return blocksArr, nil
 }
 
-func (c *Client) GetTransactions(txIds []string) (*[]map[string]interface{}, error) {
+func (c *Client) GetTransactions(txIds []string) ([]types.TxEntry, error) {
+	return c.GetTransactionsCtx(context.Background(), txIds)
+}
+
+// GetTransactionsCtx возвращает найденные транзакции; отсутствующие у демона пропускаются
+func (c *Client) GetTransactionsCtx(ctx context.Context, txIds []string) ([]types.TxEntry, error) {
 	req := UniversalRequest{
 		"txs_hashes":     txIds,
 		"decode_as_json": false,
 		"prunable":       false,
 	}
 
-	response, err := c.cycleCall(cGetTransaction, req.MarshalToJson())
+	response, err := c.cycleCall(ctx, cGetTransaction, req.MarshalToJson())
 	if err != nil {
 		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetTransaction, err)
 	}
 
-	resp := make(UniversalRequest)
-	resp.FromJson(response)
+	var resp struct {
+		Status string `json:"status"`
+		Txs    []struct {
+			types.TxEntry
+			AsHex string `json:"as_hex"`
+		} `json:"txs"`
+	}
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 2, cGetTransaction, err)
+	}
 
-	if strings.ToLower(resp["status"].(string)) != "ok" {
+	if strings.ToLower(resp.Status) != "ok" {
 		return nil, fmt.Errorf("error, request is not ok!")
 	}
 
-	var txs []map[string]interface{}
-	for _, val := range resp["txs"].([]interface{}) {
-		vvv := val.(map[string]interface{})
-		data, _ := hex.DecodeString(vvv["as_hex"].(string))
-		hexTx := types.Transaction{Raw: data}
-		hexTx.ParseTx()
-
-		v64arr := []uint64{}
-		for _, val := range vvv["output_indices"].([]interface{}) {
-			v64, _ := toUint64(val)
-			v64arr = append(v64arr, v64)
+	txs := make([]types.TxEntry, 0, len(resp.Txs))
+	for _, val := range resp.Txs {
+		tx := val.TxEntry
+		if tx.Blob, err = hex.DecodeString(val.AsHex); err != nil {
+			return nil, fmt.Errorf(cErrorTxtTemplate, 3, cGetTransaction, err)
 		}
 
-		tx := map[string]interface{}{
-			"hash":           vvv["tx_hash"],
-			"output_indices": v64arr,
-			"block_height":   vvv["block_height"],
-			"extra":          []byte(hexTx.Extra),
-			"data":           data,
+		parsed := types.Transaction{Raw: tx.Blob}
+		if err := parsed.ParseTx(); err == nil {
+			tx.Extra = []byte(parsed.Extra)
 		}
+
 		txs = append(txs, tx)
 	}
 
-	return &txs, nil
+	return txs, nil
 }
 
 func (c *Client) GetOutputDistribution(currentBlockHeight uint64) ([]uint64, error) {
+	return c.GetOutputDistributionCtx(context.Background(), currentBlockHeight)
+}
+
+func (c *Client) GetOutputDistributionCtx(ctx context.Context, currentBlockHeight uint64) ([]uint64, error) {
 	req := UniversalRequest{
 		"amounts":     []uint64{0},
 		"from_height": currentBlockHeight - 100,
@@ -122,7 +170,7 @@ func (c *Client) GetOutputDistribution(currentBlockHeight uint64) ([]uint64, err
 		"cumulative":  true,
 	}
 
-	response, err := c.cycleCall(cGetOutputDistribution, req.MarshalToBlob())
+	response, err := c.cycleCall(ctx, cGetOutputDistribution, req.MarshalToBlob())
 	if err != nil {
 		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetOutputDistribution, err)
 	}
@@ -170,74 +218,71 @@ func (c *Client) GetOutputDistribution(currentBlockHeight uint64) ([]uint64, err
 	return distributions, nil
 }
 
-func (c *Client) GetOuts(indxs []uint64) ([]*map[string]interface{}, error) {
-	outs := []*map[string]interface{}{}
+func (c *Client) GetOuts(indxs []uint64) ([]types.OutKey, error) {
+	return c.GetOutsCtx(context.Background(), indxs)
+}
+
+func (c *Client) GetOutsCtx(ctx context.Context, indxs []uint64) ([]types.OutKey, error) {
+	outs := []map[string]interface{}{}
 	for _, val := range indxs {
-		outs = append(outs, &map[string]interface{}{
+		outs = append(outs, map[string]interface{}{
 			"amount": 0,
 			"index":  val,
 		})
 	}
 
 	req := UniversalRequest{
-		"outputs": outs,
+		"outputs":  outs,
+		"get_txid": true,
 	}
 
-	response, err := c.cycleCall(cGetOuts, req.MarshalToJson())
+	response, err := c.cycleCall(ctx, cGetOuts, req.MarshalToJson())
 	if err != nil {
 		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetOuts, err)
 	}
 
-	resp := make(UniversalRequest)
-	resp.FromJson(response)
+	var resp struct {
+		Status string         `json:"status"`
+		Outs   []types.OutKey `json:"outs"`
+	}
+	if err := json.Unmarshal(response, &resp); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 2, cGetOuts, err)
+	}
 
-	if strings.ToLower(resp["status"].(string)) != "ok" {
+	if strings.ToLower(resp.Status) != "ok" {
 		return nil, fmt.Errorf("error, request is not ok!")
 	}
 
-	arr := resp["outs"].([]interface{})
-	for i, val := range outs {
-		t := arr[i].(map[string]interface{})
-		(*val)["key"] = t["key"]
-		(*val)["mask"] = t["mask"]
+	if len(resp.Outs) != len(indxs) {
+		return nil, fmt.Errorf("outs count mismatch: got %d, expected %d", len(resp.Outs), len(indxs))
 	}
 
-	return outs, nil
+	return resp.Outs, nil
 }
 
-func (c *Client) SendRawTransaction(inHex string, do_not_relay bool) (*map[string]interface{}, error) {
+func (c *Client) SendRawTransaction(inHex string, do_not_relay bool) (*types.SendResult, error) {
+	return c.SendRawTransactionCtx(context.Background(), inHex, do_not_relay)
+}
+
+// SendRawTransactionCtx отправляет транзакцию; при отказе возвращает и результат
+// с флагами причин, и ошибку types.ErrTxRejected
+func (c *Client) SendRawTransactionCtx(ctx context.Context, inHex string, do_not_relay bool) (*types.SendResult, error) {
 	req := UniversalRequest{
 		"tx_as_hex":    inHex,
 		"do_not_relay": do_not_relay,
 	}
 
-	response, err := c.cycleCall(cSendRawTransaction, req.MarshalToJson())
+	response, err := c.cycleCall(ctx, cSendRawTransaction, req.MarshalToJson())
 	if err != nil {
-		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetOuts, err)
-	}
-
-	resp := make(UniversalRequest)
-	resp.FromJson(response)
-
-	fullresp := map[string]interface{}{}
-	for key, val := range resp {
-		switch v := val.(type) {
-		case bool:
-			if v {
-				fullresp[key] = val
-			}
-		default:
-			if !slices.Contains([]string{"credits", "top_hash"}, key) {
-				fullresp[key] = val
-			}
-		}
+		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cSendRawTransaction, err)
 	}
 
-	if strings.ToLower(resp["status"].(string)) != "ok" {
-		return &fullresp, fmt.Errorf("error, request is not ok!")
+	result := &types.SendResult{}
+	if err := json.Unmarshal(response, result); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 2, cSendRawTransaction, err)
 	}
 
-	return &fullresp, nil
+	return result, result.Err()
 }
 
 func toUint64(v interface{}) (uint64, bool) {
@@ -255,11 +300,15 @@ func toUint64(v interface{}) (uint64, bool) {
 	}
 }
 
-func (c *Client) GetFeeEstimate() (*[]uint64, error) {
+func (c *Client) GetFeeEstimate() (*types.FeeEstimate, error) {
+	return c.GetFeeEstimateCtx(context.Background())
+}
+
+func (c *Client) GetFeeEstimateCtx(ctx context.Context) (*types.FeeEstimate, error) {
 	template := `{"jsonrpc":"2.0","method":"%s","params":{},"id":"0"}`
 	reqBody := []byte(fmt.Sprintf(template, cGetFeeEstimate))
 
-	response, err := c.cycleCall(cJSON_RPC, reqBody)
+	response, err := c.cycleCall(ctx, cJSON_RPC, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetFeeEstimate, err)
 	}
@@ -272,18 +321,26 @@ func (c *Client) GetFeeEstimate() (*[]uint64, error) {
 		return nil, fmt.Errorf("error, request is not ok!")
 	}
 
-	fees := []uint64{}
-	for _, fee := range result["fees"].([]interface{}) {
-		if v, ok := toUint64(fee); ok {
-			fees = append(fees, v)
+	estimate := &types.FeeEstimate{}
+	estimate.Fee, _ = toUint64(result["fee"])
+	estimate.QuantizationMask, _ = toUint64(result["quantization_mask"])
+	if fees, ok := result["fees"].([]interface{}); ok {
+		for _, fee := range fees {
+			if v, ok := toUint64(fee); ok {
+				estimate.Fees = append(estimate.Fees, v)
+			}
 		}
 	}
 
-	return &fees, nil
+	return estimate, nil
 }
 
 func (c *Client) GetHeight() (string, uint64, error) {
-	response, err := c.cycleCall(cGetHeight, nil)
+	return c.GetHeightCtx(context.Background())
+}
+
+func (c *Client) GetHeightCtx(ctx context.Context) (string, uint64, error) {
+	response, err := c.cycleCall(ctx, cGetHeight, nil)
 	if err != nil {
 		return "", 0, fmt.Errorf(cErrorTxtTemplate, 1, cGetHeight, err)
 	}
@@ -297,3 +354,5 @@ func (c *Client) GetHeight() (string, uint64, error) {
 	val, _ := toUint64(resp["height"])
 	return resp["hash"].(string), val, nil
 }
+
+var _ types.RPCClientContext = (*Client)(nil)
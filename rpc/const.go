@@ -1,5 +1,8 @@
 package rpc
 
+import "time"
+
+// cRPCDaemonNodes - публичные узлы mainnet
 var cRPCDaemonNodes = []string{
 	"https://xmr.unshakled.net:443",
 	"https://xmr1.doggett.tech:18089",
@@ -17,14 +20,35 @@ var cRPCDaemonNodes = []string{
 
 const (
 	// cRetriesCount     = 3
+	cRetryBaseDelay = 100 * time.Millisecond
+	cRetryMaxDelay  = 5 * time.Second
+	// cMaxIdleConnsPerHost - размер пула keep-alive соединений к одному узлу
+	cMaxIdleConnsPerHost = 16
+
 	cErrorTxtTemplate = "Error(%d) of calling %s method: %w"
 	cJSON_RPC         = "/json_rpc"
 
 	cGetBlocks             = "/get_blocks_by_height.bin"
+	cGetBlocksFast         = "/get_blocks.bin"
 	cGetTransaction        = "/get_transactions"
 	cGetOutputDistribution = "/get_output_distribution.bin"
 	cGetOuts               = "/get_outs"
 	cSendRawTransaction    = "/send_raw_transaction"
 	cGetHeight             = "/get_height"
 	cGetFeeEstimate        = "get_fee_estimate"
+
+	cGetTransactionPool       = "/get_transaction_pool"
+	cGetTransactionPoolHashes = "/get_transaction_pool_hashes.bin"
+	cIsKeyImageSpent          = "/is_key_image_spent"
+
+	// Методы /json_rpc
+	cGetInfo                = "get_info"
+	cGetBlockHeaderByHeight = "get_block_header_by_height"
+	cGetBlockHeaderByHash   = "get_block_header_by_hash"
+	cGetBlockHeadersRange   = "get_block_headers_range"
+	cGetLastBlockHeader     = "get_last_block_header"
+	cGetBlock               = "get_block"
+	cGetVersion             = "get_version"
+	cHardForkInfo           = "hard_fork_info"
+	cGetOutputHistogram     = "get_output_histogram"
 )
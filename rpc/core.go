@@ -2,39 +2,71 @@ package rpc
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/0xAF4/go-monero/util"
 )
 
 func (c *Client) getRandomDaemonNode() string {
 	if c.hostList != nil && len(*c.hostList) > 0 {
 		return (*c.hostList)[rand.IntN(len(*c.hostList))]
 	}
+	if c.network != util.MainnetParams {
+		return fmt.Sprintf("http://127.0.0.1:%d", c.network.RPCPort)
+	}
 	return cRPCDaemonNodes[rand.IntN(len(cRPCDaemonNodes))]
 }
 
-func (c *Client) cycleCall(method string, data []byte) ([]byte, error) {
+// backoff возвращает паузу перед повторной попыткой try (с нуля):
+// экспоненциальный рост от cRetryBaseDelay до cRetryMaxDelay со случайным разбросом
+func backoff(try int) time.Duration {
+	delay := cRetryMaxDelay
+	if try < 16 {
+		delay = min(cRetryBaseDelay<<try, cRetryMaxDelay)
+	}
+	// Половина паузы фиксирована, половина случайна, чтобы воркеры не ретраили синхронно
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (c *Client) cycleCall(ctx context.Context, method string, data []byte) ([]byte, error) {
 	var (
 		response []byte
 		err      error
 	)
 
-	for try := 0; try < c.retriesCount; try++ {
-		response, err = c.call(method, data)
+	// retriesCount <= 0 - одна попытка без повторов
+	attempts := max(c.retriesCount, 1)
+	for try := 0; try < attempts; try++ {
+		if try > 0 {
+			timer := time.NewTimer(backoff(try - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, fmt.Errorf("cancelled after %d attempts: %w", try, errors.Join(ctx.Err(), err))
+			case <-timer.C:
+			}
+		}
+
+		response, err = c.call(ctx, method, data)
 		if err == nil {
 			return response, nil
 		}
-		time.Sleep(time.Millisecond * 100)
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("cancelled after %d attempts: %w", try+1, err)
+		}
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", c.retriesCount, err)
+	return nil, fmt.Errorf("failed after %d attempts: %w", attempts, err)
 }
 
-func (c *Client) call(method string, data []byte) ([]byte, error) {
+func (c *Client) call(ctx context.Context, method string, data []byte) ([]byte, error) {
 	url := c.getRandomDaemonNode() + method
 
 	contentType := "application/json"
@@ -42,11 +74,13 @@ func (c *Client) call(method string, data []byte) ([]byte, error) {
 		contentType = "application/octet-stream"
 	}
 
-	httpClient := &http.Client{
-		Timeout: c.timeout,
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request to %s: %w", url, err)
 	}
+	req.Header.Set("Content-Type", contentType)
 
-	resp, err := httpClient.Post(url, contentType, bytes.NewReader(data))
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("http post to %s failed: %w", url, err)
 	}
@@ -0,0 +1,309 @@
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/0xAF4/go-monero/types"
+)
+
+// jsonRPCCall вызывает метод /json_rpc и разбирает поле result в result
+func (c *Client) jsonRPCCall(ctx context.Context, method string, params UniversalRequest, result any) error {
+	if params == nil {
+		params = UniversalRequest{}
+	}
+	req := UniversalRequest{
+		"jsonrpc": "2.0",
+		"id":      "0",
+		"method":  method,
+		"params":  params,
+	}
+
+	response, err := c.cycleCall(ctx, cJSON_RPC, req.MarshalToJson())
+	if err != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 1, method, err)
+	}
+
+	var envelope struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 2, method, err)
+	}
+	if envelope.Error != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 3, method, fmt.Errorf("rpc error %d: %s", envelope.Error.Code, envelope.Error.Message))
+	}
+
+	if err := decodeResponse(envelope.Result, result); err != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 4, method, err)
+	}
+	return nil
+}
+
+// jsonCall вызывает JSON-эндпоинт демона и разбирает ответ в result
+func (c *Client) jsonCall(ctx context.Context, method string, req UniversalRequest, result any) error {
+	response, err := c.cycleCall(ctx, method, req.MarshalToJson())
+	if err != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 1, method, err)
+	}
+
+	if err := decodeResponse(response, result); err != nil {
+		return fmt.Errorf(cErrorTxtTemplate, 2, method, err)
+	}
+	return nil
+}
+
+// decodeResponse проверяет status ответа и разбирает его в result
+func decodeResponse(data []byte, result any) error {
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	if strings.ToLower(status.Status) != "ok" {
+		return fmt.Errorf("error, request is not ok: %q", status.Status)
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (c *Client) GetInfo() (*types.DaemonInfo, error) {
+	return c.GetInfoCtx(context.Background())
+}
+
+func (c *Client) GetInfoCtx(ctx context.Context) (*types.DaemonInfo, error) {
+	info := &types.DaemonInfo{}
+	if err := c.jsonRPCCall(ctx, cGetInfo, nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *Client) GetBlockHeaderByHeight(height uint64) (*types.BlockHeader, error) {
+	return c.GetBlockHeaderByHeightCtx(context.Background(), height)
+}
+
+func (c *Client) GetBlockHeaderByHeightCtx(ctx context.Context, height uint64) (*types.BlockHeader, error) {
+	var resp struct {
+		BlockHeader types.BlockHeader `json:"block_header"`
+	}
+	if err := c.jsonRPCCall(ctx, cGetBlockHeaderByHeight, UniversalRequest{"height": height}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.BlockHeader, nil
+}
+
+func (c *Client) GetBlockHeaderByHash(hash string) (*types.BlockHeader, error) {
+	return c.GetBlockHeaderByHashCtx(context.Background(), hash)
+}
+
+func (c *Client) GetBlockHeaderByHashCtx(ctx context.Context, hash string) (*types.BlockHeader, error) {
+	var resp struct {
+		BlockHeader types.BlockHeader `json:"block_header"`
+	}
+	if err := c.jsonRPCCall(ctx, cGetBlockHeaderByHash, UniversalRequest{"hash": hash}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.BlockHeader, nil
+}
+
+// GetBlockHeadersRange возвращает заголовки блоков start..end включительно
+func (c *Client) GetBlockHeadersRange(start, end uint64) ([]types.BlockHeader, error) {
+	return c.GetBlockHeadersRangeCtx(context.Background(), start, end)
+}
+
+func (c *Client) GetBlockHeadersRangeCtx(ctx context.Context, start, end uint64) ([]types.BlockHeader, error) {
+	if start > end {
+		return nil, fmt.Errorf("invalid range: start %d > end %d", start, end)
+	}
+
+	var resp struct {
+		Headers []types.BlockHeader `json:"headers"`
+	}
+	req := UniversalRequest{
+		"start_height": start,
+		"end_height":   end,
+	}
+	if err := c.jsonRPCCall(ctx, cGetBlockHeadersRange, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Headers, nil
+}
+
+func (c *Client) GetLastBlockHeader() (*types.BlockHeader, error) {
+	return c.GetLastBlockHeaderCtx(context.Background())
+}
+
+func (c *Client) GetLastBlockHeaderCtx(ctx context.Context) (*types.BlockHeader, error) {
+	var resp struct {
+		BlockHeader types.BlockHeader `json:"block_header"`
+	}
+	if err := c.jsonRPCCall(ctx, cGetLastBlockHeader, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp.BlockHeader, nil
+}
+
+func (c *Client) GetBlockByHeight(height uint64) (*types.BlockResult, error) {
+	return c.GetBlockByHeightCtx(context.Background(), height)
+}
+
+func (c *Client) GetBlockByHeightCtx(ctx context.Context, height uint64) (*types.BlockResult, error) {
+	return c.getBlock(ctx, UniversalRequest{"height": height})
+}
+
+func (c *Client) GetBlockByHash(hash string) (*types.BlockResult, error) {
+	return c.GetBlockByHashCtx(context.Background(), hash)
+}
+
+func (c *Client) GetBlockByHashCtx(ctx context.Context, hash string) (*types.BlockResult, error) {
+	return c.getBlock(ctx, UniversalRequest{"hash": hash})
+}
+
+func (c *Client) getBlock(ctx context.Context, req UniversalRequest) (*types.BlockResult, error) {
+	var resp struct {
+		types.BlockResult
+		Blob string `json:"blob"`
+	}
+	if err := c.jsonRPCCall(ctx, cGetBlock, req, &resp); err != nil {
+		return nil, err
+	}
+
+	block := resp.BlockResult
+	var err error
+	if block.Blob, err = hex.DecodeString(resp.Blob); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 5, cGetBlock, err)
+	}
+	return &block, nil
+}
+
+func (c *Client) GetTransactionPool() (*types.TxPool, error) {
+	return c.GetTransactionPoolCtx(context.Background())
+}
+
+func (c *Client) GetTransactionPoolCtx(ctx context.Context) (*types.TxPool, error) {
+	var resp struct {
+		Transactions []struct {
+			types.PoolTx
+			TxBlob string `json:"tx_blob"`
+		} `json:"transactions"`
+		SpentKeyImages []types.PoolKeyImage `json:"spent_key_images"`
+	}
+	if err := c.jsonCall(ctx, cGetTransactionPool, UniversalRequest{}, &resp); err != nil {
+		return nil, err
+	}
+
+	pool := &types.TxPool{SpentKeyImages: resp.SpentKeyImages}
+	for _, val := range resp.Transactions {
+		tx := val.PoolTx
+		var err error
+		if tx.Blob, err = hex.DecodeString(val.TxBlob); err != nil {
+			return nil, fmt.Errorf(cErrorTxtTemplate, 3, cGetTransactionPool, err)
+		}
+		pool.Transactions = append(pool.Transactions, tx)
+	}
+	return pool, nil
+}
+
+func (c *Client) GetTransactionPoolHashes() ([]types.Hash, error) {
+	return c.GetTransactionPoolHashesCtx(context.Background())
+}
+
+func (c *Client) GetTransactionPoolHashesCtx(ctx context.Context) ([]types.Hash, error) {
+	response, err := c.cycleCall(ctx, cGetTransactionPoolHashes, UniversalRequest{}.MarshalToBlob())
+	if err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 1, cGetTransactionPoolHashes, err)
+	}
+
+	resp := make(UniversalRequest)
+	if err := resp.FromPortableStorate(response); err != nil {
+		return nil, fmt.Errorf(cErrorTxtTemplate, 2, cGetTransactionPoolHashes, err)
+	}
+
+	if status, _ := resp["status"].(string); strings.ToLower(status) != "ok" {
+		return nil, fmt.Errorf("error, request is not ok!")
+	}
+
+	// tx_hashes - склеенные 32-байтные хеши; в пустом пуле поле отсутствует
+	hashes, err := readHashBlob(resp["tx_hashes"])
+	if err != nil {
+		return nil, fmt.Errorf("tx_hashes: %w", err)
+	}
+	return hashes, nil
+}
+
+// IsKeyImageSpent возвращает состояние каждого образа ключа (hex) в порядке запроса
+func (c *Client) IsKeyImageSpent(keyImages []string) ([]types.KeyImageStatus, error) {
+	return c.IsKeyImageSpentCtx(context.Background(), keyImages)
+}
+
+func (c *Client) IsKeyImageSpentCtx(ctx context.Context, keyImages []string) ([]types.KeyImageStatus, error) {
+	var resp struct {
+		SpentStatus []types.KeyImageStatus `json:"spent_status"`
+	}
+	if err := c.jsonCall(ctx, cIsKeyImageSpent, UniversalRequest{"key_images": keyImages}, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.SpentStatus) != len(keyImages) {
+		return nil, fmt.Errorf("spent_status count mismatch: got %d, expected %d", len(resp.SpentStatus), len(keyImages))
+	}
+	return resp.SpentStatus, nil
+}
+
+func (c *Client) GetVersion() (*types.DaemonVersion, error) {
+	return c.GetVersionCtx(context.Background())
+}
+
+func (c *Client) GetVersionCtx(ctx context.Context) (*types.DaemonVersion, error) {
+	version := &types.DaemonVersion{}
+	if err := c.jsonRPCCall(ctx, cGetVersion, nil, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+func (c *Client) HardForkInfo() (*types.HardForkInfo, error) {
+	return c.HardForkInfoCtx(context.Background())
+}
+
+func (c *Client) HardForkInfoCtx(ctx context.Context) (*types.HardForkInfo, error) {
+	info := &types.HardForkInfo{}
+	if err := c.jsonRPCCall(ctx, cHardForkInfo, nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *Client) GetOutputHistogram(prm types.HistogramRequest) ([]types.HistogramEntry, error) {
+	return c.GetOutputHistogramCtx(context.Background(), prm)
+}
+
+func (c *Client) GetOutputHistogramCtx(ctx context.Context, prm types.HistogramRequest) ([]types.HistogramEntry, error) {
+	amounts := prm.Amounts
+	if amounts == nil {
+		amounts = []uint64{}
+	}
+	req := UniversalRequest{
+		"amounts":       amounts,
+		"min_count":     prm.MinCount,
+		"max_count":     prm.MaxCount,
+		"unlocked":      prm.Unlocked,
+		"recent_cutoff": prm.RecentCutoff,
+	}
+
+	var resp struct {
+		Histogram []types.HistogramEntry `json:"histogram"`
+	}
+	if err := c.jsonRPCCall(ctx, cGetOutputHistogram, req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Histogram, nil
+}
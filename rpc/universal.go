@@ -18,6 +18,8 @@ func (u UniversalRequest) MarshalToBlob() []byte {
 		switch v := val.(type) {
 		case string:
 			sVal = levin.BoostString(v)
+		case uint8:
+			sVal = levin.BoostUint8(v)
 		case uint64:
 			sVal = levin.BoostUint64(v)
 		case []uint64:
@@ -0,0 +1,115 @@
+package test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_Address_NetworksAndKinds(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	_, pubView := util.NewKeyPair()
+	paymentID := [util.PaymentIDLength]byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	// Первый символ base58 определяется префиксом (для 0x35 - "9" или "A" в зависимости от ключа)
+	leading := map[util.Network][3]string{
+		util.Mainnet:  {"4", "4", "8"},
+		util.Testnet:  {"9A", "A", "B"},
+		util.Stagenet: {"5", "5", "7"},
+	}
+
+	for network, chars := range leading {
+		standard, err := util.NewAddress(network, util.AddressStandard, *pubSpend, *pubView)
+		if err != nil {
+			t.Fatalf("%s: NewAddress returned error: %v", network, err)
+		}
+		subaddress, _ := util.NewAddress(network, util.AddressSubaddress, *pubSpend, *pubView)
+		integrated, err := standard.WithPaymentID(paymentID)
+		if err != nil {
+			t.Fatalf("%s: WithPaymentID returned error: %v", network, err)
+		}
+
+		for i, addr := range []util.Address{standard, integrated, subaddress} {
+			encoded := addr.String()
+			if !strings.ContainsRune(chars[i], rune(encoded[0])) {
+				t.Fatalf("%s %s: unexpected leading char in %s", network, addr.Kind, encoded)
+			}
+
+			parsed, err := util.ParseAddress(encoded)
+			if err != nil {
+				t.Fatalf("%s %s: ParseAddress returned error: %v", network, addr.Kind, err)
+			}
+			if parsed != addr {
+				t.Fatalf("%s %s: parsed %+v != %+v", network, addr.Kind, parsed, addr)
+			}
+		}
+
+		if len(integrated.String()) != 106 || len(standard.String()) != 95 {
+			t.Fatalf("%s: unexpected address lengths", network)
+		}
+		if pid, err := util.ExtractPaymentID(integrated.String()); err != nil || string(pid) != string(paymentID[:]) {
+			t.Fatalf("%s: ExtractPaymentID returned %x, err: %v", network, pid, err)
+		}
+		if integrated.Standard() != standard {
+			t.Fatalf("%s: Standard() does not strip payment ID", network)
+		}
+		if !util.IsSubAddress(subaddress.String()) || util.IsSubAddress(integrated.String()) {
+			t.Fatalf("%s: IsSubAddress mismatch", network)
+		}
+		if _, err := subaddress.WithPaymentID(paymentID); !errors.Is(err, util.ErrInvalidAddress) {
+			t.Fatalf("%s: subaddress accepted payment ID", network)
+		}
+	}
+}
+
+func Test_Address_ParseErrors(t *testing.T) {
+	parsed, err := util.ParseAddress(Address)
+	if err != nil || parsed.Network != util.Mainnet || parsed.Kind != util.AddressStandard {
+		t.Fatalf("unexpected parse result %+v, err: %v", parsed, err)
+	}
+	if parsed.String() != Address {
+		t.Fatalf("re-encoded address %s != %s", parsed.String(), Address)
+	}
+
+	// Испорченный символ - ошибка контрольной суммы
+	broken := []byte(Address)
+	if broken[20] == 'a' {
+		broken[20] = 'b'
+	} else {
+		broken[20] = 'a'
+	}
+	if _, err := util.ParseAddress(string(broken)); !errors.Is(err, util.ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress, got %v", err)
+	}
+
+	// Ключ, не являющийся точкой кривой
+	var notPoint util.Key
+	notPoint[0] = 2
+	for i := 1; i < util.KeyLength; i++ {
+		notPoint[i] = 0xff
+	}
+	notPoint[31] = 0x7f
+	invalid := util.EncodeAddress(util.MainnetAddressPrefix, notPoint, parsed.PublicViewKey)
+	if _, err := util.ParseAddress(invalid); !errors.Is(err, util.ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress for invalid key, got %v", err)
+	}
+
+	// JSON - строка base58
+	data, err := json.Marshal(struct{ Address util.Address }{parsed})
+	if err != nil || !strings.Contains(string(data), Address) {
+		t.Fatalf("unexpected JSON %s, err: %v", data, err)
+	}
+	var decoded struct{ Address util.Address }
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Address != parsed {
+		t.Fatalf("JSON round trip failed: %v", err)
+	}
+
+	if n, err := util.ParseNetwork("stagenet"); err != nil || n != util.Stagenet {
+		t.Fatalf("ParseNetwork returned %s, err: %v", n, err)
+	}
+}
@@ -0,0 +1,440 @@
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+// fakeDaemon отдаёт детерминированные выходы для построения колец
+type fakeDaemon struct {
+	height  uint64
+	total   uint64
+	txs     map[string][]uint64
+	outputs map[uint64]types.Mixin
+}
+
+func newFakeDaemon() *fakeDaemon {
+	return &fakeDaemon{
+		height:  3_000_000,
+		total:   100_000,
+		txs:     make(map[string][]uint64),
+		outputs: make(map[uint64]types.Mixin),
+	}
+}
+
+func (d *fakeDaemon) GetTransactions(txIds []string) ([]types.TxEntry, error) {
+	result := []types.TxEntry{}
+	for _, txId := range txIds {
+		indices, ok := d.txs[txId]
+		if !ok {
+			return nil, fmt.Errorf("unknown tx %s", txId)
+		}
+		hash, _ := hex.DecodeString(txId)
+		result = append(result, types.TxEntry{Hash: types.Hash(hash), OutputIndices: indices})
+	}
+	return result, nil
+}
+
+func (d *fakeDaemon) GetOutputDistribution(currentBlockHeight uint64) ([]uint64, error) {
+	return []uint64{d.total}, nil
+}
+
+func (d *fakeDaemon) GetOuts(indxs []uint64) ([]types.OutKey, error) {
+	result := []types.OutKey{}
+	for _, idx := range indxs {
+		out, ok := d.outputs[idx]
+		if !ok {
+			seed := binary.LittleEndian.AppendUint64(nil, idx)
+			out = types.Mixin{
+				Dest: keyHash(util.HashToScalar(seed, []byte("dest")).PubKey()),
+				Mask: keyHash(util.HashToScalar(seed, []byte("mask")).PubKey()),
+			}
+		}
+		result = append(result, types.OutKey{Key: out.Dest, Mask: out.Mask, Unlocked: true})
+	}
+	return result, nil
+}
+
+func (d *fakeDaemon) GetHeight() (string, uint64, error) {
+	return "", d.height, nil
+}
+
+func (d *fakeDaemon) GetFeeEstimate() (*types.FeeEstimate, error) {
+	return &types.FeeEstimate{
+		Fee:              20000,
+		Fees:             []uint64{20000, 80000, 320000, 4000000},
+		QuantizationMask: 10000,
+	}, nil
+}
+
+func keyHash(k *util.Key) types.Hash {
+	return types.Hash(*k)
+}
+
+// paymentTx строит транзакцию с одним выходом на субадрес кошелька (без входов и подписей)
+func paymentTx(t *testing.T, table *util.SubaddressTable, index util.SubaddressIndex, amount util.Amount) *types.Transaction {
+	privView := table.PrivateViewKey()
+	pubSpend := table.PublicSpendKey()
+	spend, view := util.SubaddressKeys(&privView, &pubSpend, index)
+
+	r, _ := util.NewKeyPair()
+	R := *r.PubKey()
+	if !index.IsPrimary() {
+		R = util.ScalarMult(r, &spend)
+	}
+	derivation, _ := util.GenerateKeyDerivation(&view, r)
+	P, _ := util.DerivePublicKey(&derivation, 0, &spend)
+	viewTag, _ := util.DeriveViewTag(&derivation, 0)
+	encAmount, _ := util.EncryptRctAmount(&derivation, 0, amount)
+	_, outPk, err := types.CalcOutPk(&derivation, 0, amount, spend[:])
+	if err != nil {
+		t.Fatalf("CalcOutPk returned error: %v", err)
+	}
+
+	tx := &types.Transaction{
+		Version: 2,
+		Extra:   append([]byte{util.TX_EXTRA_TAG_PUBKEY}, R[:]...),
+		Outputs: []types.TxOutput{{
+			Target:  types.Hash(P),
+			Type:    types.TxOutToTaggedKey,
+			ViewTag: types.HByte(viewTag),
+		}},
+		RctSignature: &types.RctSignature{
+			Type:     uint64(util.RCTTypeBulletproofPlus),
+			EcdhInfo: []types.Echd{{Amount: encAmount}},
+			OutPk:    []types.Hash{outPk},
+		},
+		RctSigPrunable: &types.RctSigPrunable{},
+	}
+	copy(tx.Hash[:], util.Keccak256(append(R[:], P[:]...)))
+	return tx
+}
+
+// register делает выходы транзакции доступными для колец под глобальными индексами
+func (d *fakeDaemon) register(tx *types.Transaction, globalIndices ...uint64) {
+	d.txs[hex.EncodeToString(tx.Hash[:])] = globalIndices
+	for i, idx := range globalIndices {
+		d.outputs[idx] = types.Mixin{Dest: tx.Outputs[i].Target, Mask: tx.RctSignature.OutPk[i]}
+	}
+}
+
+// receive имитирует входящий платёж на субадрес кошелька
+func (d *fakeDaemon) receive(t *testing.T, table *util.SubaddressTable, index util.SubaddressIndex, amount util.Amount, globalIndex uint64) types.OwnedOutput {
+	tx := paymentTx(t, table, index, amount)
+
+	owned, _, err := tx.ScanOutputs(table, nil)
+	if err != nil || len(owned) != 1 {
+		t.Fatalf("ScanOutputs found %d outputs, err: %v", len(owned), err)
+	}
+
+	d.register(tx, globalIndex)
+	return owned[0]
+}
+
+func Test_TxBuilder_BuildAndValidate(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+	table.AddRange(0, 3)
+
+	daemon := newFakeDaemon()
+	in1 := daemon.receive(t, table, util.SubaddressIndex{}, 2*util.XMR, 90_000)
+	in2 := daemon.receive(t, table, util.SubaddressIndex{Major: 0, Minor: 2}, util.XMR/2, 95_000)
+
+	otherView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	destination := util.EncodeAddress(util.MainnetAddressPrefix, *otherSpend, *otherView.PubKey())
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	if err := builder.AddInput(in1); err != nil {
+		t.Fatalf("AddInput returned error: %v", err)
+	}
+	if err := builder.AddInput(in2); err != nil {
+		t.Fatalf("AddInput returned error: %v", err)
+	}
+	if err := builder.AddInput(in1); err == nil {
+		t.Fatal("AddInput accepted duplicate input")
+	}
+	if err := builder.AddDestination(destination, util.XMR); err != nil {
+		t.Fatalf("AddDestination returned error: %v", err)
+	}
+
+	// Без получателей - ошибка валидации, а не panic
+	if _, err := types.NewTxBuilder(*privSpend, *privView).Build(context.Background(), daemon); err == nil ||
+		!strings.Contains(err.Error(), "no destinations") {
+		t.Fatalf("expected validation error, got: %v", err)
+	}
+
+	builder.SetFee(util.Amount(30_000_000))
+	if err := builder.SetChange(table.Address(util.SubaddressIndex{Major: 0, Minor: 1})); err != nil {
+		t.Fatalf("SetChange returned error: %v", err)
+	}
+
+	tx, err := builder.Build(context.Background(), daemon)
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	rings, err := tx.FetchRings(daemon)
+	if err != nil {
+		t.Fatalf("FetchRings returned error: %v", err)
+	}
+	if violations := types.ValidateTransaction(tx, rings); len(violations) > 0 {
+		t.Fatalf("ValidateTransaction returned violations: %v", violations)
+	}
+
+	// Сдача должна находиться сканером кошелька
+	owned, _, err := tx.ScanOutputs(table, nil)
+	if err != nil || len(owned) != 1 {
+		t.Fatalf("expected change output, got %d, err: %v", len(owned), err)
+	}
+	expectedChange := 2*util.XMR + util.XMR/2 - util.XMR - util.Amount(30_000_000)
+	if owned[0].Amount != expectedChange || owned[0].Subaddress != (util.SubaddressIndex{Major: 0, Minor: 1}) {
+		t.Fatalf("unexpected change output: %+v", owned[0])
+	}
+
+	// Нехватка средств
+	builder = types.NewTxBuilder(*privSpend, *privView)
+	builder.AddInput(in2)
+	builder.AddDestination(destination, util.XMR)
+	builder.SetFee(1)
+	if _, err := builder.Build(context.Background(), daemon); err == nil ||
+		!strings.Contains(err.Error(), "insufficient funds") {
+		t.Fatalf("expected insufficient funds error, got: %v", err)
+	}
+}
+
+func Test_TxBuilder_AutomaticFee(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+
+	daemon := newFakeDaemon()
+	in := daemon.receive(t, table, util.SubaddressIndex{}, 3*util.XMR, 91_000)
+
+	otherView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	destination := util.EncodeAddress(util.MainnetAddressPrefix, *otherSpend, *otherView.PubKey())
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	builder.SetPriority(types.FeePriorityFast)
+	if err := builder.AddInput(in); err != nil {
+		t.Fatalf("AddInput returned error: %v", err)
+	}
+	if err := builder.AddDestination(destination, util.XMR); err != nil {
+		t.Fatalf("AddDestination returned error: %v", err)
+	}
+
+	expectedFee, err := builder.EstimateFee(daemon)
+	if err != nil {
+		t.Fatalf("EstimateFee returned error: %v", err)
+	}
+
+	tx, err := builder.Build(context.Background(), daemon)
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	if util.Amount(tx.RctSignature.TxnFee) != expectedFee {
+		t.Fatalf("fee %d, expected %d", tx.RctSignature.TxnFee, expectedFee)
+	}
+	if expectedFee%10000 != 0 {
+		t.Fatalf("fee %d is not quantized", expectedFee)
+	}
+
+	estimate, _ := daemon.GetFeeEstimate()
+	required, _ := estimate.FeeForWeight(tx.Weight(), types.FeePriorityFast)
+	if expectedFee < required {
+		t.Fatalf("fee %d is lower than required %d for weight %d", expectedFee, required, tx.Weight())
+	}
+
+	// Остаток уходит на сдачу на основной адрес
+	owned, _, err := tx.ScanOutputs(table, nil)
+	if err != nil || len(owned) != 1 {
+		t.Fatalf("expected change output, got %d, err: %v", len(owned), err)
+	}
+	if owned[0].Amount != 2*util.XMR-expectedFee {
+		t.Fatalf("unexpected change %s", owned[0].Amount)
+	}
+}
+
+func Test_Fee_WeightClawback(t *testing.T) {
+	if types.EstimateTxWeight(1, 2, 44) != types.EstimateTxSize(1, 2, 44) {
+		t.Fatal("two-output transaction must not have clawback")
+	}
+	if clawback := types.EstimateTxWeight(1, 4, 44) - types.EstimateTxSize(1, 4, 44); clawback != 460 {
+		t.Fatalf("unexpected clawback for 4 outputs: %d", clawback)
+	}
+	if fee := types.FeeForWeight(1501, 20000, 10000); fee != 30020000 {
+		t.Fatalf("unexpected fee %d", fee)
+	}
+}
+
+func Test_TxBuilder_MultiOutput(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+
+	// Получатель с основным адресом и субадресами
+	_, recipientPubSpend := util.NewKeyPair()
+	recipientView, _ := util.NewKeyPair()
+	recipient := util.NewSubaddressTable(*recipientView, *recipientPubSpend)
+	recipient.AddRange(0, types.MaxOutputs)
+
+	daemon := newFakeDaemon()
+	for outputs := types.MinOutputs; outputs <= types.MaxOutputs; outputs++ {
+		in := daemon.receive(t, table, util.SubaddressIndex{}, 20*util.XMR, uint64(50_000+outputs))
+
+		builder := types.NewTxBuilder(*privSpend, *privView)
+		if err := builder.AddInput(in); err != nil {
+			t.Fatalf("AddInput returned error: %v", err)
+		}
+
+		expected := map[util.SubaddressIndex]util.Amount{}
+		for i := 0; i < outputs-1; i++ {
+			index := util.SubaddressIndex{Major: 0, Minor: uint32(i)}
+			amount := util.XMR/10 + util.Amount(i)
+			if err := builder.AddDestination(recipient.Address(index), amount); err != nil {
+				t.Fatalf("AddDestination returned error: %v", err)
+			}
+			expected[index] = amount
+		}
+
+		tx, err := builder.Build(context.Background(), daemon)
+		if err != nil {
+			t.Fatalf("%d outputs: Build returned error: %v", outputs, err)
+		}
+		if len(tx.Outputs) != outputs || len(tx.RctSigPrunable.Bpp) != 1 {
+			t.Fatalf("%d outputs: got %d outputs and %d proofs", outputs, len(tx.Outputs), len(tx.RctSigPrunable.Bpp))
+		}
+
+		padded := 1
+		for padded < outputs {
+			padded *= 2
+		}
+		if rounds := len(tx.RctSigPrunable.Bpp[0].L); 1<<rounds != 64*padded {
+			t.Fatalf("%d outputs: proof has %d rounds, expected padding to %d", outputs, rounds, padded)
+		}
+
+		// Доказательство проходит проверку и после сериализации
+		parsed := types.Transaction{Raw: tx.Serialize()}
+		parsed.ParseTx()
+		parsed.ParseRctSig()
+		if err := parsed.VerifyBulletproofPlus(); err != nil {
+			t.Fatalf("%d outputs: VerifyBulletproofPlus after round trip returned error: %v", outputs, err)
+		}
+
+		rings, err := tx.FetchRings(daemon)
+		if err != nil {
+			t.Fatalf("FetchRings returned error: %v", err)
+		}
+		if violations := types.ValidateTransaction(&parsed, rings); len(violations) > 0 {
+			t.Fatalf("%d outputs: ValidateTransaction returned violations: %v", outputs, violations)
+		}
+
+		// Получатель находит все свои выходы, в том числе на субадреса
+		received, _, err := parsed.CheckSubaddressOutputs(recipient)
+		if err != nil {
+			t.Fatalf("%d outputs: CheckSubaddressOutputs returned error: %v", outputs, err)
+		}
+		if len(received) != len(expected) {
+			t.Fatalf("%d outputs: recipient found %d outputs, expected %d", outputs, len(received), len(expected))
+		}
+		for index, amount := range expected {
+			if received[index] != amount {
+				t.Fatalf("%d outputs: subaddress %d/%d received %s, expected %s", outputs, index.Major, index.Minor, received[index], amount)
+			}
+		}
+
+		// Сдача возвращается отправителю
+		if owned, _, err := parsed.ScanOutputs(table, nil); err != nil || len(owned) != 1 {
+			t.Fatalf("%d outputs: expected change output, got %d, err: %v", outputs, len(owned), err)
+		}
+
+		estimate, _ := daemon.GetFeeEstimate()
+		required, _ := estimate.FeeForWeight(parsed.Weight(), types.FeePriorityNormal)
+		if util.Amount(parsed.RctSignature.TxnFee) < required {
+			t.Fatalf("%d outputs: fee %d is lower than required %d", outputs, parsed.RctSignature.TxnFee, required)
+		}
+	}
+
+	// 16 получателей без сдачи - тоже допустимые 16 выходов
+	fee := util.XMR / 100
+	in := daemon.receive(t, table, util.SubaddressIndex{}, types.MaxOutputs*util.XMR+fee, 50_100)
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	builder.AddInput(in)
+	builder.SetFee(fee)
+	for i := 0; i < types.MaxOutputs; i++ {
+		builder.AddDestination(recipient.Address(util.SubaddressIndex{Minor: uint32(i)}), util.XMR)
+	}
+	tx, err := builder.Build(context.Background(), daemon)
+	if err != nil {
+		t.Fatalf("16 destinations without change: Build returned error: %v", err)
+	}
+	parsed := types.Transaction{Raw: tx.Serialize()}
+	parsed.ParseTx()
+	parsed.ParseRctSig()
+	if len(parsed.Outputs) != types.MaxOutputs {
+		t.Fatalf("16 destinations without change: got %d outputs", len(parsed.Outputs))
+	}
+	if received, _, err := parsed.CheckSubaddressOutputs(recipient); err != nil || len(received) != types.MaxOutputs {
+		t.Fatalf("recipient found %d outputs, err: %v", len(received), err)
+	}
+	if owned, _, err := parsed.ScanOutputs(table, nil); err != nil || len(owned) != 0 {
+		t.Fatalf("expected no change output, got %d, err: %v", len(owned), err)
+	}
+}
+
+func Test_TxBuilder_TooManyOutputs(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+
+	daemon := newFakeDaemon()
+	in := daemon.receive(t, table, util.SubaddressIndex{}, 20*util.XMR, 60_000)
+
+	otherView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	destination := util.EncodeAddress(util.MainnetAddressPrefix, *otherSpend, *otherView.PubKey())
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	builder.AddInput(in)
+	for i := 0; i < types.MaxOutputs; i++ {
+		builder.AddDestination(destination, util.XMR/10)
+	}
+	if _, err := builder.Build(context.Background(), daemon); err == nil ||
+		!strings.Contains(err.Error(), "too many destinations") {
+		t.Fatalf("expected too many destinations error, got: %v", err)
+	}
+
+	// Низкоуровневый API тоже отклоняет больше 16 выходов
+	tx := types.NewEmptyTransaction()
+	for i := 0; i <= types.MaxOutputs; i++ {
+		tx.WriteOutput(types.TxPrm{"address": destination, "amount": util.XMR, "change_address": false})
+	}
+	if err := tx.CalcExtra(); err == nil || !strings.Contains(err.Error(), "too many outputs") {
+		t.Fatalf("expected too many outputs error from CalcExtra, got: %v", err)
+	}
+	if err := tx.CalcOutputs(); err == nil {
+		t.Fatal("CalcOutputs accepted 17 outputs")
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+func coin(index uint64, amount util.Amount, height uint64) types.OwnedOutput {
+	return types.OwnedOutput{Index: index, Amount: amount, BlockHeight: height}
+}
+
+func selectedIndices(selection *types.CoinSelection) map[uint64]bool {
+	result := make(map[uint64]bool)
+	for _, o := range selection.Inputs {
+		result[o.Index] = true
+	}
+	return result
+}
+
+func Test_CoinSelection_Strategies(t *testing.T) {
+	estimate := &types.FeeEstimate{Fees: []uint64{20000, 80000, 320000, 4000000}, QuantizationMask: 10000}
+	outputs := []types.OwnedOutput{
+		coin(0, 5*util.XMR, 1000),
+		coin(1, 2*util.XMR, 900),
+		coin(2, util.XMR, 950),
+		coin(3, util.XMR/2, 800),
+		coin(4, 10*util.XMR, 1995), // заблокирован: младше 10 блоков
+		coin(5, 20*util.XMR, 0),    // в пуле
+	}
+	locked := coin(6, 30*util.XMR, 500)
+	locked.UnlockTime = 5000
+	outputs = append(outputs, locked)
+
+	selector := &types.CoinSelector{ChainHeight: 2000, Destinations: 1, FeeEstimate: estimate}
+
+	selector.Strategy = types.SelectMinInputs
+	selection, err := selector.Select(outputs, 4*util.XMR)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if len(selection.Inputs) != 1 || selection.Inputs[0].Index != 0 {
+		t.Fatalf("min inputs selected %v", selectedIndices(selection))
+	}
+	if selection.Total != selection.Fee+selection.Change+4*util.XMR || selection.Fee == 0 {
+		t.Fatalf("unbalanced selection: %+v", selection)
+	}
+
+	selector.Strategy = types.SelectOldestFirst
+	selection, err = selector.Select(outputs, util.XMR)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if indices := selectedIndices(selection); len(indices) != 2 || !indices[3] || !indices[1] {
+		t.Fatalf("oldest first selected %v", indices)
+	}
+
+	// 2 + 1 XMR покрывают цель с минимальной сдачей
+	selector.Strategy = types.SelectMinChange
+	selection, err = selector.Select(outputs, 3*util.XMR-util.XMR/100)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if indices := selectedIndices(selection); len(indices) != 2 || !indices[1] || !indices[2] {
+		t.Fatalf("min change selected %v", indices)
+	}
+
+	selector.Strategy = types.SelectConsolidateDust
+	selection, err = selector.Select(outputs, util.XMR/10)
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if len(selection.Inputs) != 4 {
+		t.Fatalf("consolidate dust selected %v", selectedIndices(selection))
+	}
+
+	for _, strategy := range []types.SelectionStrategy{types.SelectMinInputs, types.SelectMinChange, types.SelectOldestFirst, types.SelectConsolidateDust} {
+		selector.Strategy = strategy
+		if _, err := selector.Select(outputs, 9*util.XMR); !errors.Is(err, types.ErrInsufficientFunds) {
+			t.Fatalf("%s: expected ErrInsufficientFunds, got %v", strategy, err)
+		}
+	}
+}
+
+func Test_CoinSelection_InputLimit(t *testing.T) {
+	outputs := []types.OwnedOutput{}
+	for i := 0; i < 20; i++ {
+		outputs = append(outputs, coin(uint64(i), util.XMR, 100))
+	}
+
+	selector := &types.CoinSelector{ChainHeight: 200, Strategy: types.SelectMinInputs}
+	selection, err := selector.Select(outputs, 16*util.XMR)
+	if err != nil || len(selection.Inputs) != types.MaxInputs {
+		t.Fatalf("expected %d inputs, got %v", types.MaxInputs, err)
+	}
+
+	if _, err := selector.Select(outputs, 17*util.XMR); !errors.Is(err, types.ErrTooManyInputs) {
+		t.Fatalf("expected ErrTooManyInputs, got %v", err)
+	}
+
+	selector.Strategy = types.SelectConsolidateDust
+	selection, err = selector.Select(outputs, util.XMR)
+	if err != nil || len(selection.Inputs) != types.MaxInputs {
+		t.Fatalf("consolidate dust expected %d inputs, got %v", types.MaxInputs, err)
+	}
+}
+
+func Test_OwnedOutput_IsUnlocked(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	o := coin(0, util.XMR, 100)
+	if o.IsUnlocked(109, now) || !o.IsUnlocked(110, now) {
+		t.Fatal("10-block spendable age is not respected")
+	}
+
+	o.UnlockTime = 200
+	if o.IsUnlocked(199, now) || !o.IsUnlocked(200, now) {
+		t.Fatal("height unlock_time is not respected")
+	}
+
+	o.UnlockTime = uint64(now.Unix()) + 3600
+	if o.IsUnlocked(1000, now) || !o.IsUnlocked(1000, now.Add(time.Hour)) {
+		t.Fatal("timestamp unlock_time is not respected")
+	}
+}
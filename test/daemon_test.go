@@ -35,17 +35,14 @@ func Test_DaemonRPC_GetTransactions(t *testing.T) {
 		t.Fatalf("GetTransactions returned error: %v", err)
 	}
 
-	if resp == nil {
-		t.Fatal("response is nil")
+	if len(resp) == 0 {
+		t.Fatal("transaction not found")
 	}
-	extra := (*resp)[0]["extra"]
-	fmt.Println(extra)
-	fmt.Printf("extra: %x\n", extra)
-
-	for _, tx := range *resp {
-		fmt.Printf("TxHash: %s\n", tx["hash"])
-		fmt.Printf("Extra: %x\n", tx["extra"])
-		fmt.Printf("BlockHeight: %d\n", uint64(tx["block_height"].(float64)))
+
+	for _, tx := range resp {
+		fmt.Printf("TxHash: %x\n", tx.Hash)
+		fmt.Printf("Extra: %x\n", tx.Extra)
+		fmt.Printf("BlockHeight: %d\n", tx.BlockHeight)
 		fmt.Println("====")
 	}
 }
@@ -80,7 +77,7 @@ func Test_DaemonRPC_GetOuts(t *testing.T) { //TODO: to=do
 	}
 
 	for _, out := range resp {
-		fmt.Println(*out)
+		fmt.Printf("key: %x mask: %x height: %d unlocked: %t\n", out.Key, out.Mask, out.Height, out.Unlocked)
 	}
 }
 
@@ -92,7 +89,7 @@ func Test_DaemonRPC_SendRawTransaction(t *testing.T) {
 		t.Fatalf("SendRawTransaction returned error: %v", err)
 	}
 
-	fmt.Println("Sended:", *ok)
+	fmt.Println("Sended:", ok.Status)
 }
 
 func Test_DaemonRPC_GetFeeEstimate(t *testing.T) {
@@ -103,7 +100,7 @@ func Test_DaemonRPC_GetFeeEstimate(t *testing.T) {
 		t.Fatalf("GetOutputDistribution returned error: %v", err)
 	}
 
-	fmt.Println("Fees:", *fees)
+	fmt.Println("Fees:", fees.Fees, "QuantizationMask:", fees.QuantizationMask)
 }
 
 func Test_DaemonRPC_GetHeight(t *testing.T) {
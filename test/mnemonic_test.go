@@ -0,0 +1,103 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+const testMnemonic = "hemlock jubilee eden hacksaw boil superior inroads epoxy exhale orders cavernous second brunt saved richly lower upgrade hitched launching deepest mostly playful layout lower eden"
+
+func Test_Mnemonic_Decode(t *testing.T) {
+	seed, lang, err := util.MnemonicToSeed(testMnemonic)
+	if err != nil {
+		t.Fatalf("MnemonicToSeed returned error: %v", err)
+	}
+	if lang != util.English {
+		t.Fatalf("unexpected language %s", lang.Name)
+	}
+	if seed.String() != "29adefc8f67515b4b4bf48031780ab9d071d24f8a674b879ce7f245c37523807" {
+		t.Fatalf("unexpected seed %s", seed.String())
+	}
+
+	mnemonic, err := util.SeedToMnemonic(seed, util.English)
+	if err != nil || mnemonic != testMnemonic {
+		t.Fatalf("round trip mismatch: %q, err: %v", mnemonic, err)
+	}
+
+	// Достаточно префиксов, регистр не важен, 24 слова - без контрольного
+	words := strings.Fields(testMnemonic)
+	short := []string{}
+	for _, w := range words {
+		short = append(short, strings.ToUpper(w[:3]))
+	}
+	if decoded, err := util.MnemonicToSeedLanguage(strings.Join(short, " "), util.English); err != nil || decoded != seed {
+		t.Fatalf("prefix decoding failed: %s, err: %v", decoded.String(), err)
+	}
+	if decoded, _, err := util.MnemonicToSeed(strings.Join(words[:24], " ")); err != nil || decoded != seed {
+		t.Fatalf("24-word decoding failed: %s, err: %v", decoded.String(), err)
+	}
+}
+
+func Test_Mnemonic_Errors(t *testing.T) {
+	words := strings.Fields(testMnemonic)
+
+	wrongChecksum := append(append([]string{}, words[:24]...), "abbey")
+	if _, _, err := util.MnemonicToSeed(strings.Join(wrongChecksum, " ")); !errors.Is(err, util.ErrMnemonicChecksum) {
+		t.Fatalf("expected ErrMnemonicChecksum, got %v", err)
+	}
+
+	unknown := append([]string{"qwerty"}, words[1:]...)
+	if _, _, err := util.MnemonicToSeed(strings.Join(unknown, " ")); !errors.Is(err, util.ErrMnemonicWord) {
+		t.Fatalf("expected ErrMnemonicWord, got %v", err)
+	}
+
+	if _, _, err := util.MnemonicToSeed(strings.Join(words[:13], " ")); !errors.Is(err, util.ErrMnemonicLength) {
+		t.Fatalf("expected ErrMnemonicLength, got %v", err)
+	}
+
+	if _, err := util.MnemonicLanguageByName("klingon"); !errors.Is(err, util.ErrMnemonicLanguage) {
+		t.Fatalf("expected ErrMnemonicLanguage, got %v", err)
+	}
+}
+
+func Test_Mnemonic_WalletKeys(t *testing.T) {
+	util.SetTest(false)
+
+	keys, err := util.KeysFromMnemonic(testMnemonic)
+	if err != nil {
+		t.Fatalf("KeysFromMnemonic returned error: %v", err)
+	}
+
+	if view := util.ViewKeyFromSpendKey(&keys.PrivateSpendKey); view != keys.PrivateViewKey {
+		t.Fatalf("view key mismatch: %s", view.String())
+	}
+	if *keys.PrivateSpendKey.PubKey() != keys.PublicSpendKey || *keys.PrivateViewKey.PubKey() != keys.PublicViewKey {
+		t.Fatal("public keys do not match private keys")
+	}
+
+	address := keys.PrimaryAddress()
+	spend, view, err := util.DecodeAddress(address)
+	if err != nil || util.Key(spend) != keys.PublicSpendKey || util.Key(view) != keys.PublicViewKey {
+		t.Fatalf("primary address %s does not decode to wallet keys, err: %v", address, err)
+	}
+	if !strings.HasPrefix(address, "4") {
+		t.Fatalf("unexpected mainnet address %s", address)
+	}
+
+	// Случайный ключ траты переживает кодирование в мнемонику
+	spendKey := util.RandomScalar()
+	mnemonic, err := util.KeysFromSeed(*spendKey).Mnemonic(nil)
+	if err != nil {
+		t.Fatalf("Mnemonic returned error: %v", err)
+	}
+	restored, err := util.KeysFromMnemonic(mnemonic)
+	if err != nil || restored.PrivateSpendKey != *spendKey {
+		t.Fatalf("restored spend key mismatch, err: %v", err)
+	}
+	if len(strings.Fields(mnemonic)) != util.MnemonicWords {
+		t.Fatalf("unexpected word count in %q", mnemonic)
+	}
+}
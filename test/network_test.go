@@ -0,0 +1,253 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/0xAF4/go-monero/levin"
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_Network_Params(t *testing.T) {
+	for _, network := range []util.Network{util.Mainnet, util.Testnet, util.Stagenet} {
+		params := network.Params()
+		if params.Network != network {
+			t.Fatalf("%s: params describe %s", network, params.Network)
+		}
+		if len(params.GenesisHashBytes()) != 32 {
+			t.Fatalf("%s: bad genesis hash %q", network, params.GenesisHash)
+		}
+
+		for _, kind := range []util.AddressKind{util.AddressStandard, util.AddressIntegrated, util.AddressSubaddress} {
+			fromParams, err := params.AddressPrefixFor(kind)
+			if err != nil {
+				t.Fatalf("%s %s: %v", network, kind, err)
+			}
+			if prefix, _ := util.AddressPrefix(network, kind); prefix != fromParams {
+				t.Fatalf("%s %s: prefix 0x%02x != 0x%02x", network, kind, prefix, fromParams)
+			}
+		}
+
+		for i := 1; i < len(params.HardForks); i++ {
+			if params.HardForks[i].Height <= params.HardForks[i-1].Height {
+				t.Fatalf("%s: hard fork heights are not increasing at v%d", network, params.HardForks[i].Version)
+			}
+		}
+
+		if height, _ := params.HardForkHeight(2); height != params.ForkV2Height {
+			t.Fatalf("%s: fork v2 height %d != %d", network, height, params.ForkV2Height)
+		}
+	}
+
+	if util.MainnetParams.NetworkID == util.TestnetParams.NetworkID || util.TestnetParams.NetworkID == util.StagenetParams.NetworkID {
+		t.Fatal("network IDs must differ")
+	}
+	if !bytes.Equal(util.MainnetParams.NetworkID[:], levin.MainnetNetworkId) {
+		t.Fatal("mainnet network ID does not match levin.MainnetNetworkId")
+	}
+	if !bytes.Equal(util.MainnetParams.GenesisHashBytes(), levin.MainnetGenesisTxByte) {
+		t.Fatal("mainnet genesis does not match levin.MainnetGenesisTxByte")
+	}
+}
+
+func Test_Network_HardForkVersion(t *testing.T) {
+	cases := []struct {
+		params *util.NetworkParams
+		height uint64
+		want   uint8
+	}{
+		{util.MainnetParams, 1, 1},
+		{util.MainnetParams, 1009826, 1},
+		{util.MainnetParams, 1009827, 2},
+		{util.MainnetParams, 2688887, 14},
+		{util.MainnetParams, 3000000, 16},
+		{util.StagenetParams, 1151719, 15},
+		{util.StagenetParams, 1151720, 16},
+		{util.RegtestParams(), 0, 1},
+		{util.RegtestParams(), 1, 16},
+	}
+
+	for _, c := range cases {
+		if got := c.params.HardForkVersion(c.height); got != c.want {
+			t.Fatalf("%s at %d: got v%d, want v%d", c.params.Network, c.height, got, c.want)
+		}
+	}
+
+	// Regtest не должен менять параметры mainnet
+	if util.RegtestParams().HardForks[1].Height == util.MainnetParams.HardForks[1].Height {
+		t.Fatal("regtest hard forks must differ from mainnet")
+	}
+	if util.MainnetParams.HardForkVersion(1) != 1 {
+		t.Fatal("RegtestParams modified mainnet params")
+	}
+}
+
+func Test_Network_RestoreHeight(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+
+	if util.MainnetParams.RestoreHeight(at) != util.RestoreHeightFromTime(at) {
+		t.Fatal("mainnet restore height differs from RestoreHeightFromTime")
+	}
+	if util.StagenetParams.RestoreHeight(at) >= util.MainnetParams.RestoreHeight(at) {
+		t.Fatal("stagenet restore height must be below mainnet")
+	}
+	if util.RegtestParams().RestoreHeight(at) != 0 {
+		t.Fatal("regtest restore height must be 0")
+	}
+}
+
+func Test_Network_AddressForNetwork(t *testing.T) {
+	util.SetTest(false)
+
+	privView, _ := util.NewKeyPair()
+	_, pubSpend := util.NewKeyPair()
+
+	stagenet := util.SubaddressAddress(util.Stagenet, privView, pubSpend, util.SubaddressIndex{Major: 0, Minor: 1})
+	if _, err := util.ParseAddressForNetwork(stagenet.String(), util.Stagenet); err != nil {
+		t.Fatalf("ParseAddressForNetwork returned error: %v", err)
+	}
+	if _, err := util.ParseAddressForNetwork(stagenet.String(), util.Mainnet); !errors.Is(err, util.ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress for stagenet address on mainnet, got %v", err)
+	}
+
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+	if table.Address(util.SubaddressIndex{Minor: 1}) != util.Subaddress(privView, pubSpend, util.SubaddressIndex{Minor: 1}) {
+		t.Fatal("table must encode mainnet addresses by default")
+	}
+	table.SetNetwork(util.Stagenet)
+	if table.Address(util.SubaddressIndex{Minor: 1}) != stagenet.String() {
+		t.Fatal("table must encode addresses of its network")
+	}
+}
+
+func Test_TxBuilder_Network(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, _ := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	_, otherView := util.NewKeyPair()
+
+	stagenet, _ := util.NewAddress(util.Stagenet, util.AddressStandard, *otherSpend, *otherView)
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	if err := builder.AddDestination(stagenet.String(), util.XMR); err == nil {
+		t.Fatal("mainnet builder must reject stagenet destination")
+	}
+
+	if err := builder.SetNetwork(util.Stagenet); err != nil {
+		t.Fatalf("SetNetwork returned error: %v", err)
+	}
+	if err := builder.AddDestination(stagenet.String(), util.XMR); err != nil {
+		t.Fatalf("AddDestination returned error: %v", err)
+	}
+
+	change, err := util.ParseAddress(builder.ChangeAddress())
+	if err != nil || change.Network != util.Stagenet {
+		t.Fatalf("change address must be a stagenet address, got %v (%v)", change.Network, err)
+	}
+
+	if err := builder.SetNetwork(util.Mainnet); err == nil {
+		t.Fatal("SetNetwork must reject destinations of another network")
+	}
+}
+
+type pipeDialer struct {
+	conn net.Conn
+}
+
+func (d pipeDialer) DialContext(context.Context, string, string) (net.Conn, error) {
+	return d.conn, nil
+}
+
+func Test_Levin_HandshakeNetwork(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+	defer serverConn.Close()
+
+	client, err := levin.NewClient("stagenet", levin.WithContextDialer(pipeDialer{clientConn}), levin.WithNetwork(util.StagenetParams))
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	defer client.Close()
+
+	syncData := client.NewCoreSyncData(1151720, util.StagenetParams.GenesisHash, 123456789, 0)
+	go client.Handshake(syncData, 1)
+
+	header := make([]byte, levin.LevinHeaderSizeBytes)
+	if _, err := io.ReadFull(serverConn, header); err != nil {
+		t.Fatalf("read header: %v", err)
+	}
+	h, err := levin.NewHeaderFromBytesBytes(header)
+	if err != nil {
+		t.Fatalf("parse header: %v", err)
+	}
+	payload := make([]byte, h.Length)
+	if _, err := io.ReadFull(serverConn, payload); err != nil {
+		t.Fatalf("read payload: %v", err)
+	}
+
+	ps, err := levin.NewPortableStorageFromBytes(payload)
+	if err != nil {
+		t.Fatalf("parse payload: %v", err)
+	}
+
+	found := 0
+	for _, section := range ps.Entries {
+		for _, e := range section.Entries() {
+			switch e.Name {
+			case "network_id":
+				if e.String() != string(util.StagenetParams.NetworkID[:]) {
+					t.Fatalf("unexpected network_id %x", e.String())
+				}
+				found++
+			case "my_port":
+				if e.Uint32() != uint32(util.StagenetParams.P2PPort) {
+					t.Fatalf("unexpected my_port %d", e.Uint32())
+				}
+				found++
+			case "top_version":
+				// current_height 1151720 - первый блок v16, вершина 1151719 ещё v15
+				if e.Uint8() != 15 {
+					t.Fatalf("unexpected top_version %d", e.Uint8())
+				}
+				found++
+			case "cumulative_difficulty":
+				if e.Uint64() != 123456789 {
+					t.Fatalf("unexpected cumulative_difficulty %d", e.Uint64())
+				}
+				found++
+			}
+		}
+	}
+	if found != 4 {
+		t.Fatalf("handshake is missing network fields, found %d of 4", found)
+	}
+
+	// Timed sync передаёт те же данные вершины
+	timedSync, err := levin.NewPortableStorageFromBytes(levin.NewRequestTimedSync(syncData).Bytes())
+	if err != nil {
+		t.Fatalf("parse timed sync: %v", err)
+	}
+	sent := levin.NewResponseTimedSync(timedSync).PayloadData
+	if sent.CurrentHeight != 1151720 || sent.CumulativeDifficulty != 123456789 || sent.TopVersion != 15 ||
+		hex.EncodeToString([]byte(sent.TopId)) != util.StagenetParams.GenesisHash {
+		t.Fatalf("unexpected timed sync payload: %+v", sent)
+	}
+
+	ids := []string{util.StagenetParams.GenesisHash}
+	if !bytes.HasSuffix(client.ChainBlockIds(ids).Bytes(), util.StagenetParams.GenesisHashBytes()) {
+		t.Fatal("chain request must end with stagenet genesis")
+	}
+	mainnet := levin.BoostChainBlockIds{Ids: ids, Genesis: levin.MainnetGenesisTxByte}
+	if !bytes.Equal(levin.BoostBlockIds(ids).Bytes(), mainnet.Bytes()) {
+		t.Fatal("BoostBlockIds must keep mainnet genesis")
+	}
+}
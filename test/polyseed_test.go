@@ -0,0 +1,114 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_Polyseed_RoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+	seed, err := util.NewPolyseed(created, 0)
+	if err != nil {
+		t.Fatalf("NewPolyseed returned error: %v", err)
+	}
+
+	phrase, err := seed.Encode()
+	if err != nil {
+		t.Fatalf("Encode returned error: %v", err)
+	}
+	if len(strings.Fields(phrase)) != util.PolyseedWords || !util.IsPolyseed(phrase) {
+		t.Fatalf("unexpected phrase %q", phrase)
+	}
+
+	decoded, err := util.DecodePolyseed(phrase)
+	if err != nil {
+		t.Fatalf("DecodePolyseed returned error: %v", err)
+	}
+	if *decoded != *seed {
+		t.Fatalf("decoded seed %+v != %+v", decoded, seed)
+	}
+
+	// Дата округляется вниз до шага, высота - с запасом до даты создания
+	if birthday := decoded.BirthdayTime(); birthday.After(created) || created.Sub(birthday) > util.PolyseedTimeStep*time.Second {
+		t.Fatalf("unexpected birthday %s", birthday)
+	}
+	if height := decoded.RestoreHeight(); height < 3_000_000 || height > util.RestoreHeightFromTime(created) {
+		t.Fatalf("unexpected restore height %d", height)
+	}
+
+	// Достаточно первых 4 букв слова
+	short := []string{}
+	for _, w := range strings.Fields(phrase) {
+		if len(w) > 4 {
+			w = w[:4]
+		}
+		short = append(short, strings.ToUpper(w))
+	}
+	if again, err := util.DecodePolyseed(strings.Join(short, " ")); err != nil || *again != *seed {
+		t.Fatalf("prefix decoding failed: %v", err)
+	}
+
+	keys, err := decoded.Keys()
+	if err != nil {
+		t.Fatalf("Keys returned error: %v", err)
+	}
+	spend, _ := seed.SpendKey()
+	if keys.PrivateSpendKey != spend || keys.PrivateViewKey != util.ViewKeyFromSpendKey(&spend) {
+		t.Fatal("wallet keys do not match spend key")
+	}
+	if !util.ScValid(&spend) {
+		t.Fatal("spend key is not reduced")
+	}
+
+	// Другая дата - другой ключ
+	other := *seed
+	other.Birthday++
+	otherSpend, _ := other.SpendKey()
+	if otherSpend == spend {
+		t.Fatal("birthday does not affect key derivation")
+	}
+}
+
+func Test_Polyseed_Errors(t *testing.T) {
+	seed, _ := util.NewPolyseed(time.Now(), 0)
+	phrase, _ := seed.Encode()
+	words := strings.Fields(phrase)
+
+	// Замена любого слова ломает контрольную сумму
+	for i := range words {
+		broken := append([]string{}, words...)
+		if broken[i] == "abandon" {
+			broken[i] = "ability"
+		} else {
+			broken[i] = "abandon"
+		}
+		if _, err := util.DecodePolyseed(strings.Join(broken, " ")); !errors.Is(err, util.ErrPolyseedChecksum) {
+			t.Fatalf("word %d: expected ErrPolyseedChecksum, got %v", i, err)
+		}
+	}
+
+	if _, err := util.DecodePolyseed(strings.Join(words[:15], " ")); !errors.Is(err, util.ErrPolyseedLength) {
+		t.Fatalf("expected ErrPolyseedLength, got %v", err)
+	}
+	if _, err := util.DecodePolyseed("monero " + strings.Join(words[1:], " ")); !errors.Is(err, util.ErrPolyseedWord) {
+		t.Fatalf("expected ErrPolyseedWord, got %v", err)
+	}
+	if _, err := util.NewPolyseed(time.Now(), 8); !errors.Is(err, util.ErrPolyseedFeatures) {
+		t.Fatalf("expected ErrPolyseedFeatures, got %v", err)
+	}
+
+	encrypted := *seed
+	encrypted.Features |= util.PolyseedEncryptedFeature
+	phrase, _ = encrypted.Encode()
+	decoded, err := util.DecodePolyseed(phrase)
+	if err != nil || !decoded.IsEncrypted() {
+		t.Fatalf("expected encrypted seed, err: %v", err)
+	}
+	if _, err := decoded.SpendKey(); !errors.Is(err, util.ErrPolyseedEncrypted) {
+		t.Fatalf("expected ErrPolyseedEncrypted, got %v", err)
+	}
+}
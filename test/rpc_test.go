@@ -0,0 +1,524 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/0xAF4/go-monero/levin"
+	"github.com/0xAF4/go-monero/rpc"
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+const heightResponse = `{"status":"OK","height":3000000,"hash":"abcd"}`
+
+func Test_RPCClient_Retries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(heightResponse))
+	}))
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 5, &[]string{server.URL})
+	defer client.Close()
+
+	started := time.Now()
+	_, height, err := client.GetHeightCtx(context.Background())
+	if err != nil {
+		t.Fatalf("GetHeightCtx returned error: %v", err)
+	}
+	if height != 3000000 || calls.Load() != 3 {
+		t.Fatalf("unexpected height %d after %d calls", height, calls.Load())
+	}
+	// Две паузы: не меньше 50 мс и 100 мс (половина базовой паузы и её удвоения)
+	if elapsed := time.Since(started); elapsed < 150*time.Millisecond {
+		t.Fatalf("retries did not back off, took %s", elapsed)
+	}
+
+	calls.Store(-100)
+	if _, _, err := rpc.NewDaemonRPCClient(time.Second, 2, &[]string{server.URL}).GetHeight(); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+
+	// Без повторов запрос всё равно выполняется один раз
+	calls.Store(0)
+	if _, _, err := rpc.NewDaemonRPCClient(time.Second, 0, &[]string{server.URL}).GetHeight(); err == nil ||
+		!strings.Contains(err.Error(), "failed after 1 attempts") || strings.Contains(err.Error(), "%!") || calls.Load() != 1 {
+		t.Fatalf("expected a single failed attempt, got %d calls, err: %v", calls.Load(), err)
+	}
+	calls.Store(10)
+	if _, height, err := rpc.NewDaemonRPCClient(time.Second, 0, &[]string{server.URL}).GetHeight(); err != nil || height != 3000000 {
+		t.Fatalf("expected height with zero retries, got %d, err: %v", height, err)
+	}
+}
+
+func Test_RPCClient_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := rpc.NewDaemonRPCClient(time.Minute, 5, &[]string{server.URL})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	started := time.Now()
+	_, _, err := client.GetHeightCtx(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Fatalf("cancellation took %s", elapsed)
+	}
+
+	// Отменённый заранее контекст не должен приводить к запросу
+	if _, err := client.GetFeeEstimateCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled for cancelled context, got %v", err)
+	}
+}
+
+func Test_RPCClient_ConnectionReuse(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(heightResponse))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 1, &[]string{server.URL})
+	defer client.Close()
+
+	for i := 0; i < 10; i++ {
+		if _, _, err := client.GetHeight(); err != nil {
+			t.Fatalf("GetHeight returned error: %v", err)
+		}
+	}
+	if conns.Load() != 1 {
+		t.Fatalf("expected a single pooled connection, got %d", conns.Load())
+	}
+}
+
+func Test_RPCClient_TypedResponses(t *testing.T) {
+	const txHash = "5a0247682c4170b643150434198a04d73270b98dd4c112c852ee01efaec30c19"
+	const key = "1111111111111111111111111111111111111111111111111111111111111111"
+	const mask = "2222222222222222222222222222222222222222222222222222222222222222"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get_transactions":
+			w.Write([]byte(`{"status":"OK","txs":[{"tx_hash":"` + txHash + `","as_hex":"` + hexTx + `",
+				"block_height":3100000,"block_timestamp":1700000000,"confirmations":12,
+				"output_indices":[101,102],"in_pool":false,"double_spend_seen":true}],"missed_tx":["00"]}`))
+		case "/get_outs":
+			w.Write([]byte(`{"status":"OK","outs":[{"key":"` + key + `","mask":"` + mask + `",
+				"unlocked":true,"height":3000000,"txid":"` + txHash + `"}]}`))
+		case "/send_raw_transaction":
+			w.Write([]byte(`{"status":"Failed","reason":"","double_spend":true,"fee_too_low":true,"not_relayed":false}`))
+		}
+	}))
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 1, &[]string{server.URL})
+	defer client.Close()
+
+	txs, err := client.GetTransactions([]string{txHash})
+	if err != nil {
+		t.Fatalf("GetTransactions returned error: %v", err)
+	}
+	if len(txs) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(txs))
+	}
+	tx := txs[0]
+	if hex.EncodeToString(tx.Hash[:]) != txHash || tx.BlockHeight != 3100000 || tx.Confirmations != 12 ||
+		!tx.DoubleSpendSeen || tx.InPool || len(tx.OutputIndices) != 2 || tx.OutputIndices[1] != 102 {
+		t.Fatalf("unexpected tx entry: %+v", tx)
+	}
+	if hex.EncodeToString(tx.Blob) != hexTx || len(tx.Extra) == 0 {
+		t.Fatal("blob or extra not decoded")
+	}
+
+	outs, err := client.GetOuts([]uint64{5})
+	if err != nil {
+		t.Fatalf("GetOuts returned error: %v", err)
+	}
+	if hex.EncodeToString(outs[0].Key[:]) != key || hex.EncodeToString(outs[0].Mask[:]) != mask ||
+		!outs[0].Unlocked || outs[0].Height != 3000000 || hex.EncodeToString(outs[0].Txid[:]) != txHash {
+		t.Fatalf("unexpected out key: %+v", outs[0])
+	}
+	if _, err := client.GetOuts([]uint64{5, 6}); err == nil {
+		t.Fatal("expected error for outs count mismatch")
+	}
+
+	result, err := client.SendRawTransaction("00", false)
+	if !errors.Is(err, types.ErrTxRejected) {
+		t.Fatalf("expected ErrTxRejected, got %v", err)
+	}
+	if result == nil || result.Accepted() || !result.DoubleSpend || !result.FeeTooLow {
+		t.Fatalf("unexpected send result: %+v", result)
+	}
+	if reasons := result.Reasons(); len(reasons) != 2 || reasons[0] != "double_spend" || reasons[1] != "fee_too_low" {
+		t.Fatalf("unexpected reasons: %v", reasons)
+	}
+}
+
+// fakeJSONRPC отвечает на методы /json_rpc заранее заданными result
+func fakeJSONRPC(t *testing.T, results map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string                 `json:"method"`
+			Params map[string]interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("bad json_rpc request: %v", err)
+			return
+		}
+		result, ok := results[req.Method]
+		if !ok {
+			w.Write([]byte(`{"jsonrpc":"2.0","id":"0","error":{"code":-32601,"message":"Method not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":"0","result":` + result + `}`))
+	}
+}
+
+func Test_RPCClient_DaemonEndpoints(t *testing.T) {
+	const hash = "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3"
+	header := `{"major_version":16,"minor_version":16,"timestamp":1700000000,"prev_hash":"` + hash + `",
+		"nonce":7,"height":3000000,"depth":2,"hash":"` + hash + `","difficulty":300000000000,
+		"reward":600000000000,"block_weight":1500,"num_txes":3,"pow_hash":"","miner_tx_hash":"` + hash + `"}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json_rpc", fakeJSONRPC(t, map[string]string{
+		"get_info":                   `{"status":"OK","height":3000001,"target_height":0,"nettype":"mainnet","synchronized":true,"top_block_hash":"` + hash + `"}`,
+		"get_block_header_by_height": `{"status":"OK","block_header":` + header + `}`,
+		"get_block_header_by_hash":   `{"status":"OK","block_header":` + header + `}`,
+		"get_last_block_header":      `{"status":"OK","block_header":` + header + `}`,
+		"get_block_headers_range":    `{"status":"OK","headers":[` + header + `,` + header + `]}`,
+		"get_block":                  `{"status":"OK","block_header":` + header + `,"blob":"0a0b","tx_hashes":["` + hash + `"],"json":"{}"}`,
+		"get_version":                `{"status":"OK","version":196621,"release":true}`,
+		"hard_fork_info":             `{"status":"OK","version":16,"enabled":true,"earliest_height":2689608}`,
+		"get_output_histogram":       `{"status":"OK","histogram":[{"amount":0,"total_instances":100,"unlocked_instances":90,"recent_instances":5}]}`,
+	}))
+	mux.HandleFunc("/get_transaction_pool", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","transactions":[{"id_hash":"` + hash + `","tx_blob":"0102","fee":30000,"weight":1500,"relayed":true}],
+			"spent_key_images":[{"id_hash":"` + hash + `","txs_hashes":["` + hash + `"]}]}`))
+	})
+	mux.HandleFunc("/get_transaction_pool_hashes.bin", func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := hex.DecodeString(hash + hash)
+		w.Write((&levin.PortableStorage{Entries: []levin.Entry{
+			{Name: "status", Serializable: levin.BoostString("OK")},
+			{Name: "tx_hashes", Serializable: levin.BoostString(string(raw))},
+		}}).Bytes())
+	})
+	mux.HandleFunc("/is_key_image_spent", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK","spent_status":[0,1,2]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 1, &[]string{server.URL})
+	defer client.Close()
+
+	info, err := client.GetInfo()
+	if err != nil || info.Height != 3000001 || !info.Synchronized || info.Nettype != "mainnet" ||
+		hex.EncodeToString(info.TopBlockHash[:]) != hash {
+		t.Fatalf("GetInfo: %+v, %v", info, err)
+	}
+
+	byHeight, err := client.GetBlockHeaderByHeight(3000000)
+	if err != nil || byHeight.Height != 3000000 || byHeight.MajorVersion != 16 || byHeight.NumTxes != 3 ||
+		byHeight.PowHash != (types.Hash{}) || hex.EncodeToString(byHeight.Hash[:]) != hash {
+		t.Fatalf("GetBlockHeaderByHeight: %+v, %v", byHeight, err)
+	}
+	if _, err := client.GetBlockHeaderByHash(hash); err != nil {
+		t.Fatalf("GetBlockHeaderByHash: %v", err)
+	}
+	if last, err := client.GetLastBlockHeader(); err != nil || last.Reward != 600000000000 {
+		t.Fatalf("GetLastBlockHeader: %+v, %v", last, err)
+	}
+	if headers, err := client.GetBlockHeadersRange(3000000, 3000001); err != nil || len(headers) != 2 {
+		t.Fatalf("GetBlockHeadersRange: %d headers, %v", len(headers), err)
+	}
+	if _, err := client.GetBlockHeadersRange(2, 1); err == nil {
+		t.Fatal("expected error for inverted range")
+	}
+
+	block, err := client.GetBlockByHeight(3000000)
+	if err != nil || !bytes.Equal(block.Blob, []byte{0x0a, 0x0b}) || len(block.TxHashes) != 1 || block.BlockHeader.Height != 3000000 {
+		t.Fatalf("GetBlockByHeight: %+v, %v", block, err)
+	}
+
+	pool, err := client.GetTransactionPool()
+	if err != nil || len(pool.Transactions) != 1 || pool.Transactions[0].Fee != 30000 ||
+		!bytes.Equal(pool.Transactions[0].Blob, []byte{1, 2}) || len(pool.SpentKeyImages[0].TxHashes) != 1 {
+		t.Fatalf("GetTransactionPool: %+v, %v", pool, err)
+	}
+
+	hashes, err := client.GetTransactionPoolHashes()
+	if err != nil || len(hashes) != 2 || hex.EncodeToString(hashes[1][:]) != hash {
+		t.Fatalf("GetTransactionPoolHashes: %x, %v", hashes, err)
+	}
+
+	statuses, err := client.IsKeyImageSpent([]string{hash, hash, hash})
+	if err != nil || statuses[0].IsSpent() || statuses[1] != types.KeyImageSpentInChain || statuses[2] != types.KeyImageSpentInPool {
+		t.Fatalf("IsKeyImageSpent: %v, %v", statuses, err)
+	}
+	if _, err := client.IsKeyImageSpent([]string{hash}); err == nil {
+		t.Fatal("expected error for spent_status count mismatch")
+	}
+
+	version, err := client.GetVersion()
+	if err != nil || version.Major() != 3 || version.Minor() != 13 || !version.Release {
+		t.Fatalf("GetVersion: %+v, %v", version, err)
+	}
+
+	fork, err := client.HardForkInfo()
+	if err != nil || fork.Version != 16 || !fork.Enabled || fork.EarliestHeight != 2689608 {
+		t.Fatalf("HardForkInfo: %+v, %v", fork, err)
+	}
+
+	histogram, err := client.GetOutputHistogram(types.HistogramRequest{Amounts: []uint64{0}})
+	if err != nil || len(histogram) != 1 || histogram[0].UnlockedInstances != 90 {
+		t.Fatalf("GetOutputHistogram: %+v, %v", histogram, err)
+	}
+}
+
+func Test_RPCClient_JSONRPCError(t *testing.T) {
+	server := httptest.NewServer(fakeJSONRPC(t, map[string]string{
+		"get_info": `{"status":"BUSY"}`,
+	}))
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 1, &[]string{server.URL})
+	defer client.Close()
+
+	if _, err := client.GetInfo(); err == nil || !strings.Contains(err.Error(), "BUSY") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+	if _, err := client.GetVersion(); err == nil || !strings.Contains(err.Error(), "Method not found") {
+		t.Fatalf("expected json_rpc error, got %v", err)
+	}
+}
+
+// psArray - массив portable storage для ответов тестового демона
+type psArray struct {
+	elemType byte
+	items    []levin.Serializable
+}
+
+func (a psArray) Bytes() []byte {
+	varIn, _ := levin.VarIn(len(a.items))
+	result := append([]byte{a.elemType | levin.BoostSerializeFlagArray}, varIn...)
+	for _, item := range a.items {
+		// Тип элемента задан в заголовке массива
+		result = append(result, item.Bytes()[1:]...)
+	}
+	return result
+}
+
+func testBlockBlob(height uint64, txHashes ...[]byte) []byte {
+	return testVersionedBlockBlob(16, height, nil, txHashes...)
+}
+
+// testVersionedBlockBlob собирает блок версии version; до v15 выход майнера без view tag
+func testVersionedBlockBlob(version uint8, height uint64, extra []byte, txHashes ...[]byte) []byte {
+	blob := []byte{version, version}
+	blob = append(blob, util.EncodeVarint(1700000000)...)
+	blob = append(blob, make([]byte, 32)...) // prev_id
+	blob = append(blob, 1, 0, 0, 0)          // nonce
+	// miner tx: version, unlock_time, vin (gen), один выход, extra, rct type
+	blob = append(blob, 2)
+	blob = append(blob, util.EncodeVarint(height+60)...)
+	blob = append(blob, 1, 0xff)
+	blob = append(blob, util.EncodeVarint(height)...)
+	blob = append(blob, 1)
+	blob = append(blob, util.EncodeVarint(600000000000)...)
+	if version >= 15 {
+		blob = append(blob, types.TxOutToTaggedKey)
+		blob = append(blob, make([]byte, 32)...)
+		blob = append(blob, 0x42)
+	} else {
+		blob = append(blob, types.TxOutToKey)
+		blob = append(blob, make([]byte, 32)...)
+	}
+	blob = append(blob, util.EncodeVarint(uint64(len(extra)))...)
+	blob = append(blob, extra...)
+	blob = append(blob, 0)
+	blob = append(blob, util.EncodeVarint(uint64(len(txHashes)))...)
+	for _, h := range txHashes {
+		blob = append(blob, h...)
+	}
+	return blob
+}
+
+func Test_RPCClient_GetBlocksFast(t *testing.T) {
+	txHash := bytes.Repeat([]byte{0xaa}, 32)
+	poolHash := bytes.Repeat([]byte{0xbb}, 32)
+	removedHash := bytes.Repeat([]byte{0xcc}, 32)
+	// v14: выход майнера txout_to_key без view tag
+	v14Extra := append([]byte{1}, bytes.Repeat([]byte{0xdd}, 32)...)
+	v14Hashes := [][]byte{bytes.Repeat([]byte{0xe1}, 32), bytes.Repeat([]byte{0xe2}, 32)}
+
+	var request *levin.PortableStorage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/get_blocks.bin" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		request, _ = levin.NewPortableStorageFromBytes(body)
+
+		uint64s := func(values ...uint64) levin.Serializable {
+			items := []levin.Serializable{}
+			for _, v := range values {
+				items = append(items, levin.BoostUint64(v))
+			}
+			return psArray{levin.BoostSerializeTypeUint64, items}
+		}
+		txIndices := func(values ...uint64) levin.Serializable {
+			return levin.Section{Entries: []levin.Entry{{Name: "indices", Serializable: uint64s(values...)}}}
+		}
+
+		blocks := psArray{levin.BoostSerializeTypeObject, []levin.Serializable{
+			levin.Section{Entries: []levin.Entry{
+				{Name: "block", Serializable: levin.BoostString(testBlockBlob(1000))},
+			}},
+			levin.Section{Entries: []levin.Entry{
+				{Name: "pruned", Serializable: levin.BoostBool(true)},
+				{Name: "block", Serializable: levin.BoostString(testBlockBlob(1001, txHash))},
+				{Name: "txs", Serializable: psArray{levin.BoostSerializeTypeObject, []levin.Serializable{
+					levin.Section{Entries: []levin.Entry{
+						{Name: "blob", Serializable: levin.BoostString("\x02\x00")},
+						{Name: "prunable_hash", Serializable: levin.BoostString(string(poolHash))},
+					}},
+				}}},
+			}},
+			levin.Section{Entries: []levin.Entry{
+				{Name: "block", Serializable: levin.BoostString(testVersionedBlockBlob(14, 1002, v14Extra, v14Hashes...))},
+			}},
+		}}
+		indices := psArray{levin.BoostSerializeTypeObject, []levin.Serializable{
+			levin.Section{Entries: []levin.Entry{{Name: "indices", Serializable: psArray{levin.BoostSerializeTypeObject,
+				[]levin.Serializable{txIndices(10)}}}}},
+			levin.Section{Entries: []levin.Entry{{Name: "indices", Serializable: psArray{levin.BoostSerializeTypeObject,
+				[]levin.Serializable{txIndices(11), txIndices(12, 13)}}}}},
+			levin.Section{Entries: []levin.Entry{{Name: "indices", Serializable: psArray{levin.BoostSerializeTypeObject,
+				[]levin.Serializable{txIndices(20), txIndices(21), txIndices(22)}}}}},
+		}}
+		added := psArray{levin.BoostSerializeTypeObject, []levin.Serializable{
+			levin.Section{Entries: []levin.Entry{
+				{Name: "tx_hash", Serializable: levin.BoostString(string(poolHash))},
+				{Name: "tx_blob", Serializable: levin.BoostString("\x02\x01")},
+				{Name: "double_spend_seen", Serializable: levin.BoostBool(true)},
+			}},
+		}}
+
+		w.Write((&levin.PortableStorage{Entries: []levin.Entry{
+			{Name: "status", Serializable: levin.BoostString("OK")},
+			{Name: "blocks", Serializable: blocks},
+			{Name: "output_indices", Serializable: indices},
+			{Name: "start_height", Serializable: levin.BoostUint64(1000)},
+			{Name: "current_height", Serializable: levin.BoostUint64(1003)},
+			{Name: "pool_info_extent", Serializable: levin.BoostUint8(1)},
+			{Name: "added_pool_txs", Serializable: added},
+			{Name: "removed_pool_txids", Serializable: levin.BoostString(string(removedHash))},
+			{Name: "daemon_time", Serializable: levin.BoostUint64(1700000100)},
+		}}).Bytes())
+	}))
+	defer server.Close()
+
+	client := rpc.NewDaemonRPCClient(time.Second, 1, &[]string{server.URL})
+	defer client.Close()
+
+	var top types.Hash
+	top[0] = 1
+	result, err := client.GetBlocksFast(types.BlocksRequest{
+		BlockIds:      []types.Hash{top, {}},
+		Prune:         true,
+		RequestedInfo: types.BlocksAndPool,
+		PoolInfoSince: 1700000000,
+	})
+	if err != nil {
+		t.Fatalf("GetBlocksFast returned error: %v", err)
+	}
+
+	sent := map[string]interface{}{}
+	for _, e := range request.Entries {
+		sent[e.Name] = e.Value
+	}
+	if ids, _ := sent["block_ids"].(string); len(ids) != 64 || ids[0] != 1 {
+		t.Fatalf("block_ids not sent as hash blob: %x", ids)
+	}
+	if sent["prune"] != true || sent["requested_info"] != uint8(types.BlocksAndPool) || sent["pool_info_since"] != uint64(1700000000) {
+		t.Fatalf("unexpected request: %v", sent)
+	}
+
+	if result.StartHeight != 1000 || result.CurrentHeight != 1003 || len(result.Blocks) != 3 {
+		t.Fatalf("unexpected result: start %d, current %d, %d blocks", result.StartHeight, result.CurrentHeight, len(result.Blocks))
+	}
+	second := result.Blocks[1]
+	if second.Height != 1001 || second.Block.BlockHeight != 1001 || len(second.Block.TXs) != 1 ||
+		!bytes.Equal(second.Block.TXs[0].Hash[:], txHash) || !bytes.Equal(second.Block.TXs[0].Raw, []byte{2, 0}) {
+		t.Fatalf("unexpected second block: %+v", second.Block)
+	}
+	if len(second.PrunableHashes) != 1 || !bytes.Equal(second.PrunableHashes[0][:], poolHash) {
+		t.Fatalf("unexpected prunable hashes: %x", second.PrunableHashes)
+	}
+	if len(second.OutputIndices) != 2 || second.OutputIndices[1][1] != 13 || result.Blocks[0].OutputIndices[0][0] != 10 {
+		t.Fatalf("unexpected output indices: %v / %v", result.Blocks[0].OutputIndices, second.OutputIndices)
+	}
+
+	third := result.Blocks[2].Block
+	if third.MajorVersion != 14 || third.BlockHeight != 1002 || len(third.MinerTx.Outs) != 1 ||
+		third.MinerTx.Outs[0].Type != types.TxOutToKey || !bytes.Equal(third.MinerTx.Extra, v14Extra) {
+		t.Fatalf("unexpected v14 miner tx: %+v", third.MinerTx)
+	}
+	if third.TxsCount != 2 || len(third.TXs) != 2 || !bytes.Equal(third.TXs[1].Hash[:], v14Hashes[1]) {
+		t.Fatalf("unexpected v14 txs: %d, %+v", third.TxsCount, third.TXs)
+	}
+
+	if result.PoolInfoExtent != types.PoolInfoIncremental || result.DaemonTime != 1700000100 ||
+		len(result.AddedPoolTxs) != 1 || !result.AddedPoolTxs[0].DoubleSpendSeen ||
+		!bytes.Equal(result.AddedPoolTxs[0].Hash[:], poolHash) || len(result.RemovedPoolTxids) != 1 {
+		t.Fatalf("unexpected pool info: %+v", result)
+	}
+}
+
+func Test_ChainHistoryHeights(t *testing.T) {
+	heights := types.ChainHistoryHeights(100)
+	want := []uint64{100, 99, 98, 97, 96, 95, 94, 93, 92, 91, 90, 88, 84, 76, 60, 28, 0}
+	if fmt.Sprint(heights) != fmt.Sprint(want) {
+		t.Fatalf("got %v, want %v", heights, want)
+	}
+	if fmt.Sprint(types.ChainHistoryHeights(0)) != "[0]" || fmt.Sprint(types.ChainHistoryHeights(3)) != "[3 2 1 0]" {
+		t.Fatal("short chains must end with genesis")
+	}
+}
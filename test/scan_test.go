@@ -0,0 +1,70 @@
+package test
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_ScanOutputs_FromHex(t *testing.T) {
+	data, _ := hex.DecodeString(hexTx)
+
+	transaction := types.Transaction{Raw: data}
+	transaction.ParseTx()
+	transaction.ParseRctSig()
+	transaction.CalcHash()
+
+	pubSpend, _, _ := util.DecodeAddress(Address2)
+	privView, _ := util.ParseKeyFromHex(PrivateViewKey2)
+	table := util.NewSubaddressTable(privView, pubSpend)
+
+	var logged int
+	owned, mismatched, err := transaction.ScanOutputs(table, func(format string, args ...interface{}) {
+		logged++
+		_ = fmt.Sprintf(format, args...)
+	})
+	if err != nil {
+		t.Fatalf("ScanOutputs returned error: %v", err)
+	}
+	if len(owned) != 1 || len(mismatched) != 0 {
+		t.Fatalf("expected 1 owned output, got %d (%d mismatched)", len(owned), len(mismatched))
+	}
+	if logged == 0 {
+		t.Fatal("log hook was not called")
+	}
+
+	output := owned[0]
+	if output.Amount == 0 || output.TxHash != transaction.Hash || output.OneTimeKey != transaction.Outputs[output.Index].Target {
+		t.Fatalf("unexpected owned output: %+v", output)
+	}
+
+	commitment, err := types.CalcCommitment(output.Amount, output.Mask)
+	if err != nil {
+		t.Fatalf("CalcCommitment returned error: %v", err)
+	}
+	if commitment != transaction.RctSignature.OutPk[output.Index] {
+		t.Fatal("decoded amount and mask do not open outPk commitment")
+	}
+
+	// Без хука сканер должен работать молча
+	if _, _, err := transaction.ScanOutputs(table, nil); err != nil {
+		t.Fatalf("ScanOutputs returned error: %v", err)
+	}
+
+	// Подменённая сумма в ecdhInfo не должна зачисляться
+	transaction.RctSignature.EcdhInfo[output.Index].Amount[0] ^= 0x01
+	owned, mismatched, err = transaction.ScanOutputs(table, nil)
+	if err != nil {
+		t.Fatalf("ScanOutputs returned error: %v", err)
+	}
+	if len(owned) != 0 || len(mismatched) != 1 {
+		t.Fatalf("expected amount mismatch, got %d owned and %d mismatched", len(owned), len(mismatched))
+	}
+	if _, _, err := transaction.CheckSubaddressOutputs(table); !errors.Is(err, types.ErrAmountMismatch) {
+		t.Fatalf("expected ErrAmountMismatch, got %v", err)
+	}
+}
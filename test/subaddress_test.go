@@ -0,0 +1,219 @@
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_Subaddress_EncodeRoundTrip(t *testing.T) {
+	for _, address := range []string{Address, Address2} {
+		raw, err := util.DecodeAddressRaw(address)
+		if err != nil {
+			t.Fatalf("DecodeAddressRaw returned error: %v", err)
+		}
+		pubSpend, pubView, err := util.DecodeAddress(address)
+		if err != nil {
+			t.Fatalf("DecodeAddress returned error: %v", err)
+		}
+		if encoded := util.EncodeAddress(raw[0], pubSpend, pubView); encoded != address {
+			t.Fatalf("EncodeAddress mismatch: %s != %s", encoded, address)
+		}
+	}
+}
+
+func Test_Subaddress_TableLookup(t *testing.T) {
+	util.SetTest(false)
+	privView, _ := util.NewKeyPair()
+	_, pubSpend := util.NewKeyPair()
+
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+	table.AddRange(0, 10)
+	table.AddRange(1, 10)
+	if table.Len() != 20 {
+		t.Fatalf("unexpected table size %d", table.Len())
+	}
+
+	index := util.SubaddressIndex{Major: 1, Minor: 5}
+	spend, view := util.SubaddressKeys(privView, pubSpend, index)
+
+	address := table.Address(index)
+	if !util.IsSubAddress(address) {
+		t.Fatalf("%s is not recognised as subaddress", address)
+	}
+	if util.IsSubAddress(table.Address(util.SubaddressIndex{})) {
+		t.Fatal("primary address recognised as subaddress")
+	}
+
+	// Отправитель: R = r*D, P = Hs(8*r*C || i)*G + D
+	r, _ := util.NewKeyPair()
+	R := util.ScalarMult(r, &spend)
+	derivation, _ := util.GenerateKeyDerivation(&view, r)
+	P, _ := util.DerivePublicKey(&derivation, 0, &spend)
+
+	// Получатель: 8*a*R == 8*r*C
+	receiverDerivation, _ := util.GenerateKeyDerivation(&R, privView)
+	if receiverDerivation != derivation {
+		t.Fatal("sender and receiver derivations differ")
+	}
+	D, ok := util.DeriveSubaddressPublicKey(&P, &receiverDerivation, 0)
+	if !ok {
+		t.Fatal("DeriveSubaddressPublicKey failed")
+	}
+	found, ok := table.Lookup(D)
+	if !ok || found != index {
+		t.Fatalf("lookup returned %v, %v; expected %v", found, ok, index)
+	}
+}
+
+// Кошелёк из functional_tests/wallet_address.py monero (seed "velvet lymph giddy ...")
+// и адреса, которые для него выдаёт wallet2
+const (
+	wallet2PrivateSpendKey = "148d78d2aba7dbca5cd8f6abcfb0b3c009ffbdbea1ff373d50ed94d78286640e"
+	wallet2PrivateViewKey  = "49774391fa5e8d249fc2c5b45dadef13534bf2483dede880dac88f061e809100"
+)
+
+var wallet2Subaddresses = map[util.SubaddressIndex]string{
+	{Major: 0, Minor: 0}: "42ey1afDFnn4886T7196doS9GPMzexD9gXpsZJDwVjeRVdFCSoHnv7KPbBeGpzJBzHRCAs9UxqeoyFQMYbqSWYTfJJQAWDm",
+	{Major: 0, Minor: 1}: "84QRUYawRNrU3NN1VpFRndSukeyEb3Xpv8qZjjsoJZnTYpDYceuUTpog13D7qPxpviS7J29bSgSkR11hFFoXWk2yNdsR9WF",
+	{Major: 1, Minor: 0}: "82pP87g1Vkd3LUMssBCumk3MfyEsFqLAaGDf6oxddu61EgSFzt8gCwUD4tr3kp9TUfdPs2CnpD7xLZzyC1Ei9UsW3oyCWDf",
+	{Major: 2, Minor: 0}: "8Bdb75y2MhvbkvaBnG7vYP6DCNneLWcXqNmfPmyyDkavAUUgrHQEAhTNK3jEq69kGPDrd3i5inPivCwTvvA12eQ4SJk9iyy",
+}
+
+func Test_Subaddress_Wallet2Vectors(t *testing.T) {
+	privSpend, _ := util.ParseKeyFromHex(wallet2PrivateSpendKey)
+	privView, _ := util.ParseKeyFromHex(wallet2PrivateViewKey)
+	pubSpend := *privSpend.PubKey()
+
+	table := util.NewSubaddressTable(privView, pubSpend)
+	table.AddRange(0, 2)
+	table.AddRange(1, 1)
+	table.AddRange(2, 1)
+
+	for index, expected := range wallet2Subaddresses {
+		if address := util.SubaddressAddress(util.Mainnet, &privView, &pubSpend, index).String(); address != expected {
+			t.Fatalf("%d/%d: got %s, expected %s", index.Major, index.Minor, address, expected)
+		}
+		if address := table.Address(index); address != expected {
+			t.Fatalf("%d/%d: table address %s, expected %s", index.Major, index.Minor, address, expected)
+		}
+
+		raw, _ := util.DecodeAddressRaw(expected)
+		D, C, err := util.DecodeAddress(expected)
+		if err != nil {
+			t.Fatalf("DecodeAddress returned error: %v", err)
+		}
+		if index.IsPrimary() {
+			if raw[0] != util.MainnetAddressPrefix {
+				t.Fatalf("unexpected primary prefix %#x", raw[0])
+			}
+			continue
+		}
+		if raw[0] != util.MainnetSubaddressPrefix {
+			t.Fatalf("%d/%d: unexpected subaddress prefix %#x", index.Major, index.Minor, raw[0])
+		}
+
+		// Секрет траты субадреса b + m должен давать D из адреса wallet2
+		m := util.SubaddressSecretKey(&privView, index)
+		var spendSecret util.Key
+		util.ScAdd(&spendSecret, &privSpend, &m)
+		if *spendSecret.PubKey() != util.Key(D) {
+			t.Fatalf("%d/%d: (b + m)*G does not match wallet2 spend key", index.Major, index.Minor)
+		}
+		if util.SubaddressSpendKey(&privView, &pubSpend, index) != util.Key(D) {
+			t.Fatalf("%d/%d: SubaddressSpendKey does not match wallet2 spend key", index.Major, index.Minor)
+		}
+		if _, view := util.SubaddressKeys(&privView, &pubSpend, index); view != util.Key(C) {
+			t.Fatalf("%d/%d: a*D does not match wallet2 view key", index.Major, index.Minor)
+		}
+		if found, ok := table.Lookup(util.Key(D)); !ok || found != index {
+			t.Fatalf("%d/%d: lookup returned %v, %v", index.Major, index.Minor, found, ok)
+		}
+	}
+}
+
+// Платёж wallet2 на субадрес Address2 находится по его ключу траты D.
+// Основные ключи этого кошелька неизвестны, поэтому вывод ключей субадреса
+// здесь не проверяется - это делает Test_Subaddress_Wallet2Vectors.
+func Test_Subaddress_CheckOutputsBySpendKey(t *testing.T) {
+	data, _ := hex.DecodeString(hexTx)
+
+	transaction := types.Transaction{Raw: data}
+	transaction.ParseTx()
+	transaction.ParseRctSig()
+
+	// D субадреса стоит в таблице на месте основного ключа траты {0, 0}
+	pubSpend, _, _ := util.DecodeAddress(Address2)
+	privView, _ := util.ParseKeyFromHex(PrivateViewKey2)
+	table := util.NewSubaddressTable(privView, pubSpend)
+
+	amounts, _, err := transaction.CheckSubaddressOutputs(table)
+	if err != nil {
+		t.Fatalf("CheckSubaddressOutputs returned error: %v", err)
+	}
+	if amounts[util.SubaddressIndex{}] <= 0 {
+		t.Fatalf("unexpected amounts: %v", amounts)
+	}
+}
+
+func Test_Subaddress_AdditionalTxKeys(t *testing.T) {
+	util.SetTest(false)
+	privView, _ := util.NewKeyPair()
+	_, pubSpend := util.NewKeyPair()
+
+	table := util.NewSubaddressTable(*privView, *pubSpend)
+	table.AddRange(0, 5)
+
+	indices := []util.SubaddressIndex{{Major: 0, Minor: 0}, {Major: 0, Minor: 3}}
+
+	// Основной ключ транзакции не относится к нашему кошельку
+	_, mainPub := util.NewKeyPair()
+	extra := append([]byte{util.TX_EXTRA_TAG_PUBKEY}, mainPub[:]...)
+	extra = append(extra, util.TX_EXTRA_ADDITIONAL_PUBKEYS, byte(len(indices)))
+
+	transaction := types.Transaction{}
+	for i, index := range indices {
+		spend, view := util.SubaddressKeys(privView, pubSpend, index)
+
+		r, _ := util.NewKeyPair()
+		var R util.Key
+		if index.IsPrimary() {
+			R = *r.PubKey()
+		} else {
+			R = util.ScalarMult(r, &spend)
+		}
+		extra = append(extra, R[:]...)
+
+		derivation, _ := util.GenerateKeyDerivation(&view, r)
+		P, _ := util.DerivePublicKey(&derivation, uint64(i), &spend)
+		viewTag, _ := util.DeriveViewTag(&derivation, uint64(i))
+		transaction.Outputs = append(transaction.Outputs, types.TxOutput{
+			Target:  types.Hash(P),
+			Type:    types.TxOutToTaggedKey,
+			ViewTag: types.HByte(viewTag),
+		})
+	}
+	transaction.Extra = extra
+
+	_, additionalKeys, _, _, err := util.ParseTxExtra(extra)
+	if err != nil || len(additionalKeys) != len(indices) {
+		t.Fatalf("ParseTxExtra returned %d additional keys, err: %v", len(additionalKeys), err)
+	}
+
+	amounts, _, err := transaction.CheckSubaddressOutputs(table)
+	if err != nil {
+		t.Fatalf("CheckSubaddressOutputs returned error: %v", err)
+	}
+	for _, index := range indices {
+		if _, ok := amounts[index]; !ok {
+			t.Fatalf("output for subaddress %v not found: %v", index, amounts)
+		}
+	}
+
+	address := table.Address(util.SubaddressIndex{})
+	if _, _, err := transaction.CheckOutputs(address, hex.EncodeToString(privView[:])); err != nil {
+		t.Fatalf("CheckOutputs returned error: %v", err)
+	}
+}
@@ -23,10 +23,10 @@ func Test_Transaction_CheckBalance(t *testing.T) {
 		t.Fatalf("GetTransactions returned error: %v", err)
 	}
 
-	if resp == nil {
-		t.Fatal("response is nil")
+	if len(resp) == 0 {
+		t.Fatal("transaction not found")
 	}
-	data := (*resp)[0]["data"].([]byte)
+	data := resp[0].Blob
 
 	transaction := types.Transaction{Raw: data}
 	transaction.ParseTx()
@@ -37,7 +37,7 @@ func Test_Transaction_CheckBalance(t *testing.T) {
 	if err != nil {
 		fmt.Printf("  - TX checkOutputs error: %s", err)
 	} else {
-		fmt.Printf("  - TX checkOutputs find in tx: %.12f; PaymentID: %d", funds, paymentID)
+		fmt.Printf("  - TX checkOutputs find in tx: %s; PaymentID: %d", funds, paymentID)
 	}
 }
 
@@ -59,7 +59,7 @@ func Test_Transaction_CheckBalanceFromHex(t *testing.T) {
 	if err != nil {
 		fmt.Printf("  - TX checkOutputs error: %s", err)
 	} else {
-		fmt.Printf("  - TX checkOutputs find in tx: %.12f; PaymentID: %d", funds, paymentID)
+		fmt.Printf("  - TX checkOutputs find in tx: %s; PaymentID: %d", funds, paymentID)
 	}
 }
 
@@ -19,3 +19,38 @@ func Test_DerivePublicKey(t *testing.T) {
 	key, _ := util.DerivePublicKey(&derivation, 0, &m_spend_public_key)
 	fmt.Printf("Key: %x\n", key)
 }
+
+func Test_Amount_ParseAndFormat(t *testing.T) {
+	cases := map[string]util.Amount{
+		"1":                     util.XMR,
+		"1.5":                   1_500_000_000_000,
+		"0.000000000001":        1,
+		"18446744.073709551615": util.Amount(18446744073709551615),
+		"123456.789012345678":   123456_789012345678,
+	}
+	for s, expected := range cases {
+		amount, err := util.ParseAmount(s)
+		if err != nil {
+			t.Fatalf("ParseAmount(%q) returned error: %v", s, err)
+		}
+		if amount != expected {
+			t.Fatalf("ParseAmount(%q) = %d, expected %d", s, amount, expected)
+		}
+		if amount.String() != s {
+			t.Fatalf("String() = %q, expected %q", amount.String(), s)
+		}
+	}
+
+	for _, s := range []string{"-1", "0.0000000000001", "18446744.073709551616", "abc"} {
+		if _, err := util.ParseAmount(s); err == nil {
+			t.Fatalf("ParseAmount(%q) expected error", s)
+		}
+	}
+
+	if util.Amount(1).StringFixed() != "0.000000000001" {
+		t.Fatalf("unexpected StringFixed: %s", util.Amount(1).StringFixed())
+	}
+	if _, err := util.Amount(1).Sub(2); err == nil {
+		t.Fatal("Sub expected underflow error")
+	}
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+func Test_CLSAG_SignAndVerify(t *testing.T) {
+	const ringSize = 16
+	const realIndex = 5
+
+	util.SetTest(false)
+
+	amount := util.Key{0x40, 0x42, 0x0f}
+	message := types.Hash(*util.RandomScalar())
+
+	P := make([]util.Key, ringSize)
+	C_nonzero := make([]util.Key, ringSize)
+	for i := 0; i < ringSize; i++ {
+		_, pub := util.NewKeyPair()
+		P[i] = *pub
+		_, mask := util.NewKeyPair()
+		C_nonzero[i] = *mask
+	}
+
+	// Real output: P = p*G, C = x*G + a*H, pseudo out = y*G + a*H
+	p, pub := util.NewKeyPair()
+	P[realIndex] = *pub
+	x := util.RandomScalar()
+	y := util.RandomScalar()
+	util.AddKeys2(&C_nonzero[realIndex], x, &amount, &util.H)
+	var pseudoOut util.Key
+	util.AddKeys2(&pseudoOut, y, &amount, &util.H)
+
+	var z util.Key
+	util.ScSub(&z, x, y)
+
+	C := make([]util.Key, ringSize)
+	for i := 0; i < ringSize; i++ {
+		util.SubKeys(&C[i], &C_nonzero[i], &pseudoOut)
+	}
+
+	keyImage := types.Hash(util.GenerateKeyImage(p))
+
+	sig, err := types.ClsagGen(message, P, *p, C, z, C_nonzero, pseudoOut, realIndex, keyImage)
+	if err != nil {
+		t.Fatalf("ClsagGen returned error: %v", err)
+	}
+
+	if err := types.VerifyCLSAG(message, sig, P, C_nonzero, pseudoOut, keyImage); err != nil {
+		t.Fatalf("VerifyCLSAG returned error: %v", err)
+	}
+
+	// Tampered message must fail
+	if err := types.VerifyCLSAG(types.Hash(*util.RandomScalar()), sig, P, C_nonzero, pseudoOut, keyImage); err == nil {
+		t.Fatal("VerifyCLSAG accepted signature for another message")
+	}
+
+	// Tampered ring must fail
+	_, other := util.NewKeyPair()
+	P[0] = *other
+	if err := types.VerifyCLSAG(message, sig, P, C_nonzero, pseudoOut, keyImage); err == nil {
+		t.Fatal("VerifyCLSAG accepted signature for another ring")
+	}
+}
+
+func Test_BulletproofPlus_VerifyFromHex(t *testing.T) {
+	data, _ := hex.DecodeString(hexTx)
+
+	transaction := types.Transaction{Raw: data}
+	transaction.ParseTx()
+	transaction.ParseRctSig()
+
+	if err := transaction.VerifyBulletproofPlus(); err != nil {
+		t.Fatalf("VerifyBulletproofPlus returned error: %v", err)
+	}
+
+	proofs, err := transaction.BulletproofPlusProofs()
+	if err != nil {
+		t.Fatalf("BulletproofPlusProofs returned error: %v", err)
+	}
+	if err := types.VerifyBulletproofPlusBatch(append(proofs, proofs...)); err != nil {
+		t.Fatalf("VerifyBulletproofPlusBatch returned error: %v", err)
+	}
+
+	// Tampered commitment must fail
+	transaction.RctSignature.OutPk[0], transaction.RctSignature.OutPk[1] = transaction.RctSignature.OutPk[1], transaction.RctSignature.OutPk[0]
+	if err := transaction.VerifyBulletproofPlus(); err == nil {
+		t.Fatal("VerifyBulletproofPlus accepted tampered commitments")
+	}
+}
+
+func Test_ValidateTransaction_FromHex(t *testing.T) {
+	data, _ := hex.DecodeString(hexTx)
+
+	transaction := types.Transaction{Raw: data}
+	transaction.ParseTx()
+	transaction.ParseRctSig()
+
+	if violations := types.ValidateTransaction(&transaction, nil); len(violations) != 0 {
+		t.Fatalf("ValidateTransaction returned violations: %v", violations)
+	}
+
+	// Изменение комиссии ломает баланс коммитментов
+	transaction.RctSignature.TxnFee++
+	violations := types.ValidateTransaction(&transaction, nil)
+	if !violations.Has(types.ViolationCommitmentBalance) {
+		t.Fatalf("expected commitment balance violation, got: %v", violations)
+	}
+	transaction.RctSignature.TxnFee--
+
+	// Ключевой образ малого порядка (нейтральный элемент)
+	transaction.Inputs[0].KeyImage = types.Hash{1}
+	violations = types.ValidateTransaction(&transaction, nil)
+	if !violations.Has(types.ViolationKeyImage) {
+		t.Fatalf("expected key image violation, got: %v", violations)
+	}
+	if violations.Err() == nil {
+		t.Fatal("Err returned nil for non-empty violations")
+	}
+}
@@ -0,0 +1,871 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+	"github.com/0xAF4/go-monero/wallet"
+)
+
+// fakeChain отдаёт заранее собранные блоки через get_blocks.bin
+type fakeChain struct {
+	blocks   []types.BlockEntry
+	maxBatch int
+	requests []types.BlocksRequest
+}
+
+func (c *fakeChain) GetBlocksFastCtx(ctx context.Context, req types.BlocksRequest) (*types.BlocksResult, error) {
+	c.requests = append(c.requests, req)
+
+	// Как monerod: без start_height ответ начинается с первого общего блока истории
+	start := req.StartHeight
+	if start == 0 {
+	search:
+		for _, id := range req.BlockIds {
+			for _, entry := range c.blocks {
+				if entry.Block.GetBlockId() == hex.EncodeToString(id[:]) {
+					start = entry.Height
+					break search
+				}
+			}
+		}
+	}
+
+	result := &types.BlocksResult{StartHeight: start, CurrentHeight: c.height()}
+	for _, entry := range c.blocks {
+		if entry.Height >= start && len(result.Blocks) < c.maxBatch {
+			result.Blocks = append(result.Blocks, entry)
+		}
+	}
+	return result, nil
+}
+
+func (c *fakeChain) GetHeightCtx(ctx context.Context) (string, uint64, error) {
+	return c.blocks[len(c.blocks)-1].Block.GetBlockId(), c.height(), nil
+}
+
+func (c *fakeChain) Network() *util.NetworkParams {
+	return util.MainnetParams
+}
+
+func (c *fakeChain) height() uint64 {
+	return c.blocks[len(c.blocks)-1].Height + 1
+}
+
+// add связывает блок с вершиной и добавляет его в цепочку
+func (c *fakeChain) add(entry types.BlockEntry) {
+	if len(c.blocks) != 0 {
+		prev, _ := hex.DecodeString(c.blocks[len(c.blocks)-1].Block.GetBlockId())
+		entry.Block.PreviousBlockHash = types.Hash(prev)
+	}
+	c.blocks = append(c.blocks, entry)
+}
+
+// extend добавляет пустые блоки до высоты height (не включая)
+func (c *fakeChain) extend(height uint64) {
+	for h := c.height(); h < height; h++ {
+		c.add(testBlock(h, nil, nil))
+	}
+}
+
+// fork отбрасывает блоки с высоты height и выше
+func (c *fakeChain) fork(height uint64) {
+	c.blocks = c.blocks[:height-c.blocks[0].Height]
+}
+
+// testBlock собирает блок с выходами miner и транзакциями txs
+func testBlock(height uint64, miner *types.Transaction, txs []*types.Transaction) types.BlockEntry {
+	block := types.NewBlock()
+	block.MinerTx.Version = 2
+	block.MinerTx.Height = height
+	block.MinerTx.UnlockTime = height + 60
+	if miner != nil {
+		block.MinerTx.Outs = miner.Outputs
+		block.MinerTx.Extra = miner.Extra
+	}
+
+	entry := types.BlockEntry{Block: block, Height: height, OutputIndices: [][]uint64{nil}}
+	for i, tx := range txs {
+		block.TXs = append(block.TXs, &types.Transaction{Hash: tx.Hash, Raw: tx.Serialize()})
+		block.TxsCount++
+
+		indices := []uint64{}
+		for j := range tx.Outputs {
+			indices = append(indices, height*100+uint64(i*10+j))
+		}
+		entry.OutputIndices = append(entry.OutputIndices, indices)
+	}
+	for range block.MinerTx.Outs {
+		entry.OutputIndices[0] = append(entry.OutputIndices[0], height*100+99)
+	}
+	return entry
+}
+
+// coinbaseTx - выход miner tx с открытой суммой на основной адрес кошелька
+func coinbaseTx(table *util.SubaddressTable, amount util.Amount) *types.Transaction {
+	privView := table.PrivateViewKey()
+	pubSpend := table.PublicSpendKey()
+
+	r, R := util.NewKeyPair()
+	derivation, _ := util.GenerateKeyDerivation(privView.PubKey(), r)
+	P, _ := util.DerivePublicKey(&derivation, 0, &pubSpend)
+	viewTag, _ := util.DeriveViewTag(&derivation, 0)
+
+	return &types.Transaction{
+		Extra: append([]byte{util.TX_EXTRA_TAG_PUBKEY}, R[:]...),
+		Outputs: []types.TxOutput{{
+			Amount:  uint64(amount),
+			Target:  types.Hash(P),
+			Type:    types.TxOutToTaggedKey,
+			ViewTag: types.HByte(viewTag),
+		}},
+	}
+}
+
+func Test_Wallet_ScannerSpendAndBalance(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{
+		PrivateSpendKey: *privSpend,
+		PrivateViewKey:  *privView,
+		PublicSpendKey:  *pubSpend,
+		PublicViewKey:   *pubView,
+	}
+
+	chain := &fakeChain{maxBatch: 7}
+	scanner := wallet.NewScanner(chain, keys, 100)
+	table := scanner.Table()
+
+	payment := paymentTx(t, table, util.SubaddressIndex{Minor: 3}, 2*util.XMR)
+	chain.add(testBlock(100, coinbaseTx(table, 5*util.XMR), []*types.Transaction{payment}))
+
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if chain.requests[0].StartHeight != 100 || !chain.requests[0].Prune {
+		t.Fatalf("unexpected request: %+v", chain.requests[0])
+	}
+
+	outputs := scanner.Outputs()
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 outputs, got %d", len(outputs))
+	}
+	for _, out := range outputs {
+		if !out.HasKeyImage || out.BlockHeight != 100 {
+			t.Fatalf("unexpected output: %+v", out)
+		}
+	}
+	if !outputs[0].Coinbase || outputs[0].GlobalIndex != 10099 || outputs[1].GlobalIndex != 10000 {
+		t.Fatalf("unexpected global indices: %d, %d", outputs[0].GlobalIndex, outputs[1].GlobalIndex)
+	}
+
+	now := time.Now()
+	if b := scanner.Balance(now); b.Total != 7*util.XMR || b.Locked != 7*util.XMR || b.Unlocked != 0 {
+		t.Fatalf("unexpected balance right after receive: %+v", b)
+	}
+
+	chain.extend(150)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if scanner.Height() != 150 || scanner.ChainHeight() != 150 {
+		t.Fatalf("unexpected heights %d/%d", scanner.Height(), scanner.ChainHeight())
+	}
+	// Coinbase заблокирован до высоты 160, обычный платёж - на 10 блоков
+	if b := scanner.Balance(now); b.Unlocked != 2*util.XMR || b.Locked != 5*util.XMR {
+		t.Fatalf("unexpected balance at 150: %+v", b)
+	}
+
+	spendable := scanner.SpendableOutputs(now)
+	if len(spendable) != 1 || spendable[0].TxHash != payment.Hash {
+		t.Fatalf("unexpected spendable outputs: %+v", spendable)
+	}
+
+	// Тратим платёж, сдача - на субадрес 0/1
+	daemon := newFakeDaemon()
+	daemon.register(payment, outputs[1].GlobalIndex)
+
+	otherView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	destination := util.EncodeAddress(util.MainnetAddressPrefix, *otherSpend, *otherView.PubKey())
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	builder.AddInput(spendable[0])
+	builder.AddDestination(destination, util.XMR)
+	builder.SetFee(util.Amount(30_000_000))
+	builder.SetChange(table.Address(util.SubaddressIndex{Minor: 1}))
+	spend, err := builder.Build(context.Background(), daemon)
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	chain.add(testBlock(150, nil, []*types.Transaction{spend}))
+	chain.extend(165)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	change := 2*util.XMR - util.XMR - util.Amount(30_000_000)
+	outputs = scanner.Outputs()
+	if len(outputs) != 3 {
+		t.Fatalf("expected change output, got %d outputs", len(outputs))
+	}
+	if !outputs[1].Spent || outputs[1].SpentHeight != 150 || outputs[1].SpentTxHash != types.Hash(spend.Hash) {
+		t.Fatalf("payment must be marked spent: %+v", outputs[1])
+	}
+	if outputs[2].Amount != change || outputs[2].Subaddress != (util.SubaddressIndex{Minor: 1}) {
+		t.Fatalf("unexpected change output: %+v", outputs[2])
+	}
+	if b := scanner.Balance(now); b.Total != 5*util.XMR+change || b.Unlocked != 5*util.XMR+change || b.Locked != 0 {
+		t.Fatalf("unexpected balance at 165: %+v", b)
+	}
+}
+
+func Test_Wallet_ScannerViewOnly(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{PrivateViewKey: *privView, PublicSpendKey: *pubSpend, PublicViewKey: *pubView}
+
+	scanner := wallet.NewScanner(nil, keys, 10)
+	if !scanner.ViewOnly() {
+		t.Fatal("scanner without spend key must be view-only")
+	}
+
+	payment := paymentTx(t, scanner.Table(), util.SubaddressIndex{}, util.XMR)
+	if err := scanner.ScanBlocks([]types.BlockEntry{testBlock(11, nil, []*types.Transaction{payment})}); err == nil {
+		t.Fatal("ScanBlocks must reject a gap in heights")
+	}
+	if err := scanner.ScanBlocks([]types.BlockEntry{testBlock(10, nil, []*types.Transaction{payment})}); err != nil {
+		t.Fatalf("ScanBlocks returned error: %v", err)
+	}
+
+	outputs := scanner.Outputs()
+	if len(outputs) != 1 || outputs[0].HasKeyImage || outputs[0].Amount != util.XMR {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+	if b := scanner.Balance(time.Now()); b.Total != util.XMR || b.Locked != util.XMR {
+		t.Fatalf("unexpected balance: %+v", b)
+	}
+}
+
+// untagged переводит выходы в txout_to_key, как до v15
+func untagged(tx *types.Transaction) *types.Transaction {
+	for i := range tx.Outputs {
+		tx.Outputs[i].Type = types.TxOutToKey
+		tx.Outputs[i].ViewTag = 0
+	}
+	return tx
+}
+
+func Test_Wallet_ScannerUntaggedOutputs(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{PrivateViewKey: *privView, PublicSpendKey: *pubSpend, PublicViewKey: *pubView}
+	scanner := wallet.NewScanner(nil, keys, 10)
+	table := scanner.Table()
+
+	payment := untagged(paymentTx(t, table, util.SubaddressIndex{Minor: 2}, util.XMR))
+	// Второй, чужой выход: без view tag сдвиг на байт ломал бы разбор extra
+	_, other := util.NewKeyPair()
+	payment.Outputs = append(payment.Outputs, types.TxOutput{Type: types.TxOutToKey, Target: types.Hash(*other)})
+	payment.RctSignature.EcdhInfo = append(payment.RctSignature.EcdhInfo, types.Echd{})
+	payment.RctSignature.OutPk = append(payment.RctSignature.OutPk, types.Hash(*other))
+
+	// Выходы txout_to_key сериализуются без view tag, сразу за ними - extra
+	outs := []byte{2}
+	for _, out := range payment.Outputs {
+		outs = append(append(outs, 0, types.TxOutToKey), out.Target[:]...)
+	}
+	outs = append(append(outs, byte(len(payment.Extra))), payment.Extra...)
+	raw := payment.Serialize()
+	if !bytes.Contains(raw, outs) {
+		t.Fatalf("unexpected untagged outputs encoding: %x", raw)
+	}
+
+	parsed := &types.Transaction{Raw: raw}
+	if err := parsed.ParseTx(); err != nil {
+		t.Fatalf("ParseTx returned error: %v", err)
+	}
+	if len(parsed.Outputs) != 2 || parsed.Outputs[1].Target != types.Hash(*other) || !bytes.Equal(parsed.Extra, payment.Extra) {
+		t.Fatalf("unexpected parsed tx: %+v", parsed)
+	}
+
+	// Транзакция с неизвестным типом выхода не разбирается и пропускается с записью в лог
+	unknown := paymentTx(t, table, util.SubaddressIndex{Minor: 1}, util.XMR)
+	unknown.Outputs[0].Type = 0x01
+	if err := (&types.Transaction{Raw: unknown.Serialize()}).ParseTx(); err == nil {
+		t.Fatal("ParseTx must reject unknown output type")
+	}
+	logs := []string{}
+	scanner.SetLogger(func(format string, args ...interface{}) {
+		logs = append(logs, fmt.Sprintf(format, args...))
+	})
+
+	entry := testBlock(10, untagged(coinbaseTx(table, 3*util.XMR)), []*types.Transaction{unknown, payment})
+	if err := scanner.ScanBlocks([]types.BlockEntry{entry}); err != nil {
+		t.Fatalf("ScanBlocks returned error: %v", err)
+	}
+	if !slices.ContainsFunc(logs, func(line string) bool { return strings.Contains(line, "skipped: output 0: unknown type 0x1") }) {
+		t.Fatalf("skipped tx must be logged, got %q", logs)
+	}
+
+	outputs := scanner.Outputs()
+	if len(outputs) != 2 || !outputs[0].Coinbase || outputs[0].Amount != 3*util.XMR ||
+		outputs[1].Amount != util.XMR || outputs[1].Subaddress != (util.SubaddressIndex{Minor: 2}) {
+		t.Fatalf("unexpected outputs: %+v", outputs)
+	}
+}
+
+func Test_Wallet_ScannerReorg(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{
+		PrivateSpendKey: *privSpend,
+		PrivateViewKey:  *privView,
+		PublicSpendKey:  *pubSpend,
+		PublicViewKey:   *pubView,
+	}
+
+	chain := &fakeChain{maxBatch: 5}
+	scanner := wallet.NewScanner(chain, keys, 100)
+	table := scanner.Table()
+
+	rollbacks := []wallet.Rollback{}
+	scanner.SetRollbackHandler(func(r wallet.Rollback) {
+		rollbacks = append(rollbacks, r)
+	})
+
+	payment := paymentTx(t, table, util.SubaddressIndex{}, 2*util.XMR)
+	chain.add(testBlock(100, nil, []*types.Transaction{payment}))
+	chain.extend(112)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	daemon := newFakeDaemon()
+	daemon.register(payment, scanner.Outputs()[0].GlobalIndex)
+
+	otherView, _ := util.NewKeyPair()
+	_, otherSpend := util.NewKeyPair()
+	destination := util.EncodeAddress(util.MainnetAddressPrefix, *otherSpend, *otherView.PubKey())
+
+	builder := types.NewTxBuilder(*privSpend, *privView)
+	builder.AddInput(scanner.SpendableOutputs(time.Now())[0])
+	builder.AddDestination(destination, util.XMR)
+	builder.SetFee(util.Amount(30_000_000))
+	spend, err := builder.Build(context.Background(), daemon)
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+
+	// Старая ветка: трата в 112 и ещё один платёж в 113
+	chain.add(testBlock(112, nil, []*types.Transaction{spend}))
+	chain.add(testBlock(113, nil, []*types.Transaction{paymentTx(t, table, util.SubaddressIndex{Minor: 2}, util.XMR)}))
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if outputs := scanner.Outputs(); len(outputs) != 3 || !outputs[0].Spent {
+		t.Fatalf("unexpected outputs before reorg: %+v", outputs)
+	}
+
+	// Reorg на 2 блока: в новой ветке трата попадает в блок 114
+	chain.fork(112)
+	for h := uint64(112); h < 114; h++ {
+		entry := testBlock(h, nil, nil)
+		entry.Block.Nonce = 7
+		chain.add(entry)
+	}
+	chain.add(testBlock(114, nil, []*types.Transaction{spend}))
+	chain.extend(120)
+
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	if len(rollbacks) != 1 {
+		t.Fatalf("expected 1 rollback, got %d", len(rollbacks))
+	}
+	r := rollbacks[0]
+	if r.Height != 112 || len(r.Detached) != 2 || len(r.Removed) != 2 || len(r.Unspent) != 1 {
+		t.Fatalf("unexpected rollback: height %d, detached %d, removed %d, unspent %d",
+			r.Height, len(r.Detached), len(r.Removed), len(r.Unspent))
+	}
+	if last := chain.requests[len(chain.requests)-1]; last.StartHeight != 0 || len(last.BlockIds) < 2 {
+		t.Fatalf("reorg must be resolved with chain history, got %+v", last)
+	}
+
+	// Сдача зачислена один раз, платёж из старой ветки удалён
+	change := 2*util.XMR - util.XMR - util.Amount(30_000_000)
+	outputs := scanner.Outputs()
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 outputs after reorg, got %d", len(outputs))
+	}
+	if !outputs[0].Spent || outputs[0].SpentHeight != 114 {
+		t.Fatalf("payment must be spent at 114: %+v", outputs[0])
+	}
+	if outputs[1].Amount != change || outputs[1].BlockHeight != 114 {
+		t.Fatalf("unexpected change output: %+v", outputs[1])
+	}
+	if b := scanner.Balance(time.Now()); b.Total != change {
+		t.Fatalf("unexpected balance after reorg: %+v", b)
+	}
+	if scanner.Height() != 120 || scanner.ChainHeight() != 120 {
+		t.Fatalf("unexpected heights %d/%d", scanner.Height(), scanner.ChainHeight())
+	}
+}
+
+func Test_Wallet_ScannerLaggingDaemon(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{PrivateViewKey: *privView, PublicSpendKey: *pubSpend, PublicViewKey: *pubView}
+
+	chain := &fakeChain{maxBatch: 5}
+	scanner := wallet.NewScanner(chain, keys, 100)
+	table := scanner.Table()
+
+	rollbacks := []wallet.Rollback{}
+	scanner.SetRollbackHandler(func(r wallet.Rollback) {
+		rollbacks = append(rollbacks, r)
+	})
+
+	chain.add(testBlock(100, nil, []*types.Transaction{paymentTx(t, table, util.SubaddressIndex{}, util.XMR)}))
+	chain.extend(108)
+	chain.add(testBlock(108, nil, []*types.Transaction{paymentTx(t, table, util.SubaddressIndex{}, util.XMR)}))
+	chain.extend(112)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	full := chain.blocks
+
+	// Демон (например, другой узел за балансировщиком) отстал на 4 блока той же цепочки
+	chain.blocks = full[:len(full)-4]
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if len(rollbacks) != 0 || len(scanner.Outputs()) != 2 || scanner.Height() != 112 || scanner.ChainHeight() != 112 {
+		t.Fatalf("lagging daemon must not roll back: %d rollbacks, %d outputs, heights %d/%d",
+			len(rollbacks), len(scanner.Outputs()), scanner.Height(), scanner.ChainHeight())
+	}
+
+	// Демон догнал цепочку
+	chain.blocks = full
+	chain.extend(115)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if len(rollbacks) != 0 || scanner.Height() != 115 {
+		t.Fatalf("unexpected state after catch-up: %d rollbacks, height %d", len(rollbacks), scanner.Height())
+	}
+
+	// Reorg на более короткую ветку распознаётся по другому id на известной высоте
+	chain.fork(106)
+	entry := testBlock(106, nil, nil)
+	entry.Block.Nonce = 7
+	chain.add(entry)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	if len(rollbacks) != 1 || rollbacks[0].Height != 106 || len(rollbacks[0].Removed) != 1 {
+		t.Fatalf("unexpected rollbacks: %+v", rollbacks)
+	}
+	if len(scanner.Outputs()) != 1 || scanner.Height() != 107 {
+		t.Fatalf("unexpected state after reorg: %d outputs, height %d", len(scanner.Outputs()), scanner.Height())
+	}
+}
+
+func Test_Wallet_ChainTracker(t *testing.T) {
+	id := func(n byte) types.Hash { return types.Hash{n} }
+
+	tracker := wallet.NewChainTracker(3)
+	for h := uint64(10); h < 15; h++ {
+		if _, err := tracker.Connect(h, id(byte(h)), id(byte(h-1))); err != nil {
+			t.Fatalf("Connect(%d) returned error: %v", h, err)
+		}
+	}
+	if tracker.Len() != 3 || tracker.Base() != 12 {
+		t.Fatalf("window not trimmed: len %d, base %d", tracker.Len(), tracker.Base())
+	}
+	if detached, err := tracker.Connect(14, id(14), id(13)); err != nil || detached != nil {
+		t.Fatalf("known block must be a no-op, got %v, %v", detached, err)
+	}
+
+	detached, err := tracker.Connect(13, id(0xa3), id(12))
+	if err != nil || len(detached) != 2 || detached[0] != id(13) || detached[1] != id(14) {
+		t.Fatalf("fork at 13: detached %v, err %v", detached, err)
+	}
+	if _, err := tracker.Connect(15, id(15), id(14)); err == nil {
+		t.Fatal("Connect must reject a gap")
+	}
+
+	detached, err = tracker.Connect(14, id(0xb4), id(13))
+	if !errors.Is(err, wallet.ErrBlockNotLinked) || len(detached) != 1 || detached[0] != id(0xa3) {
+		t.Fatalf("unlinked block: detached %v, err %v", detached, err)
+	}
+	if top, topId, _ := tracker.Top(); top != 12 || topId != id(12) {
+		t.Fatalf("unexpected top %d %x", top, topId)
+	}
+
+	if _, err := tracker.Connect(11, id(0xc1), id(10)); !errors.Is(err, wallet.ErrReorgTooDeep) {
+		t.Fatalf("expected ErrReorgTooDeep, got %v", err)
+	}
+	if _, err := tracker.Connect(13, id(0xd3), id(0xff)); !errors.Is(err, wallet.ErrReorgTooDeep) {
+		t.Fatalf("expected ErrReorgTooDeep for the last block, got %v", err)
+	}
+
+	genesis := id(0xee)
+	ids := tracker.BlockIds(genesis)
+	if ids[0] != id(12) || ids[len(ids)-1] != genesis {
+		t.Fatalf("unexpected chain history %v", ids)
+	}
+}
+
+func Test_Wallet_StoreRestart(t *testing.T) {
+	util.SetTest(false)
+	types.SetTest(false)
+
+	privSpend, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{
+		PrivateSpendKey: *privSpend,
+		PrivateViewKey:  *privView,
+		PublicSpendKey:  *pubSpend,
+		PublicViewKey:   *pubView,
+	}
+
+	path := filepath.Join(t.TempDir(), "wallet.state")
+	password := []byte("correct horse")
+
+	chain := &fakeChain{maxBatch: 10}
+	scanner := wallet.NewScanner(chain, keys, 100)
+	// Субадрес за пределами lookahead по умолчанию
+	far := util.SubaddressIndex{Major: 2, Minor: 7}
+	scanner.Table().Add(far)
+
+	store := wallet.NewFileStore(path, password)
+	if err := scanner.AttachStore(store); err != nil {
+		t.Fatalf("AttachStore on empty store returned error: %v", err)
+	}
+
+	chain.add(testBlock(100, coinbaseTx(scanner.Table(), 3*util.XMR), []*types.Transaction{paymentTx(t, scanner.Table(), far, util.XMR)}))
+	chain.extend(130)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("state file was not written: %v", err)
+	}
+	if bytes.Contains(raw, []byte("outputs")) || bytes.Contains(raw, []byte("public_spend_key")) {
+		t.Fatal("state file must be encrypted")
+	}
+
+	if _, err := wallet.NewFileStore(path, []byte("wrong")).Load(); !errors.Is(err, wallet.ErrWrongPassword) {
+		t.Fatalf("expected ErrWrongPassword, got %v", err)
+	}
+	if _, err := wallet.NewFileStore(path+".missing", password).Load(); !errors.Is(err, wallet.ErrNoState) {
+		t.Fatalf("expected ErrNoState, got %v", err)
+	}
+
+	// Перезапуск: новый сканер продолжает с сохранённой высоты
+	chain.extend(165)
+	restarted := wallet.NewScanner(chain, keys, 100)
+	if err := restarted.AttachStore(wallet.NewFileStore(path, password)); err != nil {
+		t.Fatalf("AttachStore returned error: %v", err)
+	}
+	if restarted.Height() != 130 || len(restarted.Outputs()) != 2 {
+		t.Fatalf("state not restored: height %d, outputs %d", restarted.Height(), len(restarted.Outputs()))
+	}
+	if restarted.Outputs()[1].Subaddress != far || restarted.Outputs()[1].KeyImage != scanner.Outputs()[1].KeyImage {
+		t.Fatalf("unexpected restored output: %+v", restarted.Outputs()[1])
+	}
+	if _, ok := restarted.Table().Lookup(util.SubaddressSpendKey(privView, pubSpend, far)); !ok {
+		t.Fatal("restored scanner must track subaddresses of its outputs")
+	}
+
+	requests := len(chain.requests)
+	if err := restarted.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	for _, req := range chain.requests[requests:] {
+		if req.StartHeight != 0 && req.StartHeight < 130 {
+			t.Fatalf("restarted scanner must not rescan, requested %d", req.StartHeight)
+		}
+	}
+	if restarted.Height() != 165 || len(restarted.Outputs()) != 2 {
+		t.Fatalf("unexpected state after sync: height %d, outputs %d", restarted.Height(), len(restarted.Outputs()))
+	}
+	if b := restarted.Balance(time.Now()); b.Total != 4*util.XMR || b.Unlocked != 4*util.XMR {
+		t.Fatalf("unexpected balance: %+v", b)
+	}
+
+	history := restarted.History()
+	if len(history) != 2 || history[0].Height != 100 || history[0].Received+history[1].Received != 4*util.XMR {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+
+	// Состояние чужого кошелька не загружается
+	otherSpend, otherPubSpend := util.NewKeyPair()
+	other := keys
+	other.PrivateSpendKey, other.PublicSpendKey = *otherSpend, *otherPubSpend
+	if err := wallet.NewScanner(chain, other, 0).AttachStore(wallet.NewFileStore(path, password)); err == nil {
+		t.Fatal("AttachStore must reject state of other keys")
+	}
+}
+
+func Test_Wallet_MemoryStore(t *testing.T) {
+	store := wallet.NewMemoryStore()
+	if _, err := store.Load(); !errors.Is(err, wallet.ErrNoState) {
+		t.Fatalf("expected ErrNoState, got %v", err)
+	}
+
+	state := &wallet.State{Version: 1, Height: 42, BlockIds: []types.Hash{{1}}}
+	if err := store.Save(state); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	state.Height = 43
+
+	loaded, err := store.Load()
+	if err != nil || loaded.Height != 42 || loaded.BlockIds[0] != (types.Hash{1}) {
+		t.Fatalf("unexpected loaded state %+v, err %v", loaded, err)
+	}
+}
+
+// fakePool - пул демона для PoolWatcher
+type fakePool struct {
+	hashes    []types.Hash
+	entries   map[types.Hash]types.TxEntry
+	requested [][]string
+}
+
+func (p *fakePool) GetTransactionPoolHashesCtx(ctx context.Context) ([]types.Hash, error) {
+	return p.hashes, nil
+}
+
+func (p *fakePool) GetTransactionsCtx(ctx context.Context, txIds []string) ([]types.TxEntry, error) {
+	p.requested = append(p.requested, txIds)
+
+	result := []types.TxEntry{}
+	for _, txId := range txIds {
+		raw, _ := hex.DecodeString(txId)
+		if entry, ok := p.entries[types.Hash(raw)]; ok {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (p *fakePool) add(tx *types.Transaction) types.Hash {
+	hash := types.Hash(tx.Hash)
+	p.hashes = append(p.hashes, hash)
+	p.entries[hash] = types.TxEntry{Hash: hash, Blob: tx.Serialize(), InPool: true}
+	return hash
+}
+
+func Test_Wallet_PoolWatcher(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	privView, _ := util.NewKeyPair()
+	shop := util.NewSubaddressTable(*privView, *pubSpend)
+	shop.AddRange(0, 5)
+
+	_, otherSpend := util.NewKeyPair()
+	otherView, _ := util.NewKeyPair()
+	other := util.NewSubaddressTable(*otherView, *otherSpend)
+
+	pool := &fakePool{entries: map[types.Hash]types.TxEntry{}}
+	paid := pool.add(paymentTx(t, shop, util.SubaddressIndex{Minor: 4}, 3*util.XMR))
+	foreign := pool.add(paymentTx(t, other, util.SubaddressIndex{}, util.XMR))
+	dropped := pool.add(paymentTx(t, shop, util.SubaddressIndex{}, util.XMR))
+
+	watcher := wallet.NewPoolWatcher(pool)
+	watcher.AddWallet("shop", shop)
+
+	events, err := watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if len(events) != 2 || events[0].Kind != wallet.PoolSeen || events[0].TxHash != paid || events[1].TxHash != dropped {
+		t.Fatalf("unexpected seen events: %+v", events)
+	}
+	if events[0].Wallet != "shop" || events[0].Amount() != 3*util.XMR || events[0].Outputs[0].Subaddress.Minor != 4 {
+		t.Fatalf("unexpected seen event: %+v", events[0])
+	}
+
+	// Чужая транзакция больше не запрашивается, свои - проверяются на двойную трату
+	entry := pool.entries[dropped]
+	entry.DoubleSpendSeen = true
+	pool.entries[dropped] = entry
+
+	events, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	if len(events) != 1 || events[0].Kind != wallet.PoolDoubleSpend || events[0].TxHash != dropped {
+		t.Fatalf("unexpected double spend events: %+v", events)
+	}
+	for _, txId := range pool.requested[len(pool.requested)-1] {
+		if txId == hex.EncodeToString(foreign[:]) {
+			t.Fatal("foreign pool tx must not be fetched again")
+		}
+	}
+
+	// paid попадает в блок, dropped исчезает из пула
+	pool.hashes = nil
+	pool.entries[paid] = types.TxEntry{Hash: paid, BlockHeight: 500}
+	delete(pool.entries, dropped)
+
+	events, err = watcher.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll returned error: %v", err)
+	}
+	kinds := map[types.Hash]wallet.PoolEvent{}
+	for _, event := range events {
+		kinds[event.TxHash] = event
+	}
+	if len(events) != 2 || kinds[paid].Kind != wallet.PoolConfirmed || kinds[paid].Height != 500 || kinds[dropped].Kind != wallet.PoolEvicted {
+		t.Fatalf("unexpected final events: %+v", events)
+	}
+
+	if events, _ := watcher.Poll(context.Background()); len(events) != 0 {
+		t.Fatalf("expected no events for empty pool, got %+v", events)
+	}
+}
+
+// withPaymentID добавляет в extra зашифрованный короткий payment ID
+func withPaymentID(tx *types.Transaction, table *util.SubaddressTable, paymentID uint64) *types.Transaction {
+	privView := table.PrivateViewKey()
+	plain := binary.LittleEndian.AppendUint64(nil, paymentID)
+	// Шифрование короткого payment ID - XOR, поэтому совпадает с расшифровкой
+	_, encrypted, _ := util.DecryptShortPaymentID(tx.Extra[1:33], privView[:], plain)
+
+	tx.Extra = append(tx.Extra, util.TX_EXTRA_NONCE, 9, util.TX_EXTRA_NONCE_ENC_PAYMENT_ID)
+	tx.Extra = append(tx.Extra, encrypted...)
+	return tx
+}
+
+func Test_Wallet_PaymentTracker(t *testing.T) {
+	util.SetTest(false)
+
+	_, pubSpend := util.NewKeyPair()
+	privView, pubView := util.NewKeyPair()
+	keys := util.WalletKeys{PrivateViewKey: *privView, PublicSpendKey: *pubSpend, PublicViewKey: *pubView}
+
+	chain := &fakeChain{maxBatch: 10}
+	scanner := wallet.NewScanner(chain, keys, 100)
+	table := scanner.Table()
+
+	deposit := withPaymentID(paymentTx(t, table, util.SubaddressIndex{}, 2*util.XMR), table, 0xabc)
+	withdrawal := paymentTx(t, table, util.SubaddressIndex{Minor: 1}, util.XMR)
+	ignored := paymentTx(t, table, util.SubaddressIndex{Minor: 2}, util.XMR)
+
+	chain.add(testBlock(100, nil, []*types.Transaction{deposit, ignored}))
+	chain.add(testBlock(101, nil, []*types.Transaction{withdrawal}))
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	events := []wallet.PaymentEvent{}
+	tracker := wallet.NewPaymentTracker(chain, scanner, []uint64{3, 1}, func(e wallet.PaymentEvent) {
+		events = append(events, e)
+	})
+	tracker.WatchPaymentID(0xabc)
+	tracker.WatchTx(types.Hash(withdrawal.Hash))
+
+	update := func() []wallet.PaymentEvent {
+		t.Helper()
+		events = events[:0]
+		if err := tracker.Update(context.Background()); err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+		return events
+	}
+
+	got := update()
+	if len(got) != 2 || got[0].TxHash != types.Hash(deposit.Hash) || got[0].PaymentID != 0xabc || got[0].Depth != 1 ||
+		got[0].Confirmations != 2 || got[0].Received != 2*util.XMR {
+		t.Fatalf("unexpected first confirmations: %+v", got)
+	}
+	if got[1].TxHash != types.Hash(withdrawal.Hash) || got[1].Depth != 1 || got[1].Confirmations != 1 {
+		t.Fatalf("unexpected withdrawal event: %+v", got[1])
+	}
+	if got := update(); len(got) != 0 {
+		t.Fatalf("depths must fire once, got %+v", got)
+	}
+
+	// Блоки выше высоты сканера не засчитываются
+	chain.extend(104)
+	if got := update(); len(got) != 0 {
+		t.Fatalf("confirmations must not outrun the scanner, got %+v", got)
+	}
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+	got = update()
+	if len(got) != 2 || got[0].Depth != 3 || got[0].Confirmations != 4 || got[1].Depth != 3 || got[1].Confirmations != 3 {
+		t.Fatalf("unexpected depth 3 events: %+v", got)
+	}
+
+	// Reorg: блок 101 заменён, транзакция попадает в 102
+	chain.fork(101)
+	entry := testBlock(101, nil, nil)
+	entry.Block.Nonce = 7
+	chain.add(entry)
+	chain.add(testBlock(102, nil, []*types.Transaction{withdrawal}))
+	chain.extend(104)
+	if err := scanner.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	got = update()
+	if len(got) != 2 || !got[0].Unconfirmed || got[0].TxHash != types.Hash(withdrawal.Hash) || got[0].Height != 101 {
+		t.Fatalf("expected unconfirm event first, got %+v", got)
+	}
+	if got[1].Unconfirmed || got[1].Height != 102 || got[1].Depth != 1 || got[1].Confirmations != 2 {
+		t.Fatalf("unexpected reconfirmation: %+v", got[1])
+	}
+
+	if payments := tracker.Payments(); len(payments) != 2 || payments[1].Height != 102 {
+		t.Fatalf("unexpected payments: %+v", payments)
+	}
+
+	// Глубина 0 - платёж найден в блоке
+	included := []wallet.PaymentEvent{}
+	tracker = wallet.NewPaymentTracker(chain, scanner, []uint64{0, 2}, func(e wallet.PaymentEvent) {
+		included = append(included, e)
+	})
+	tracker.WatchTx(types.Hash(withdrawal.Hash))
+	for range 2 {
+		if err := tracker.Update(context.Background()); err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+	}
+	if len(included) != 2 || included[0].Depth != 0 || included[1].Depth != 2 || included[1].Confirmations != 2 {
+		t.Fatalf("unexpected depth 0 events: %+v", included)
+	}
+}
@@ -85,20 +85,37 @@ func (block *Block) FullfillBlockHeader() error {
 		out := TxOutput{}
 		out.Amount, _ = levin.ReadVarint(reader)
 		out.Type, _ = reader.ReadByte()
-		reader.Read(out.Target[:])
-		b, _ := reader.ReadByte()
-		out.ViewTag = HByte(b)
+		// view tag есть только у txout_to_tagged_key (с v15)
+		switch out.Type {
+		case TxOutToKey:
+			reader.Read(out.Target[:])
+		case TxOutToTaggedKey:
+			reader.Read(out.Target[:])
+			b, _ := reader.ReadByte()
+			out.ViewTag = HByte(b)
+		default:
+			return fmt.Errorf("miner tx output %d: unsupported type %#x", i-1, out.Type)
+		}
 		outs = append(outs, out)
 	}
 	block.MinerTx.Outs = outs
 	//----
 	block.MinerTx.ExtraSize, _ = levin.ReadVarint(reader)
+	if block.MinerTx.ExtraSize > uint64(reader.Len()) {
+		return fmt.Errorf("miner tx extra size %d exceeds remaining %d bytes", block.MinerTx.ExtraSize, reader.Len())
+	}
 	extra := make([]byte, block.MinerTx.ExtraSize)
 	reader.Read(extra)
 	block.MinerTx.Extra = extra
-	reader.Seek(1, io.SeekCurrent)
+	// rct_signatures (только тип RCTTypeNull) есть с версии 2
+	if block.MinerTx.Version >= 2 {
+		reader.Seek(1, io.SeekCurrent)
+	}
 	//----
 	block.TxsCount, _ = levin.ReadVarint(reader)
+	if block.TxsCount > uint64(reader.Len()/32) {
+		return fmt.Errorf("txs count %d exceeds remaining %d bytes", block.TxsCount, reader.Len())
+	}
 	for i := 0; i <= int(block.TxsCount)-1; i++ {
 		var raw []byte
 		if i < len(block.tx) {
@@ -211,8 +228,13 @@ func (b *Block) CalculateMinerBuff() []byte {
 }
 
 func (b *Block) CalculateMinerTxHash() []byte {
-	txHashingBlob := make([]byte, 96)
 	buff := b.CalculateMinerBuff()
+	if b.MinerTx.Version < 2 {
+		// у v1 хэш - просто хэш блоба без подписей
+		return util.Keccak256(buff)
+	}
+
+	txHashingBlob := make([]byte, 96)
 
 	copy(txHashingBlob, util.Keccak256(buff))
 	copy(txHashingBlob[32:], util.Keccak256([]byte{0}))
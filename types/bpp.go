@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"sync"
 
 	"filippo.io/edwards25519"
 	"github.com/0xAF4/go-monero/util"
@@ -38,12 +39,15 @@ const maxN = 64
 const maxM = 16
 
 func (t *Transaction) signBpp() (Bpp, error) {
-	bpp := Bpp{}
+	if len(t.BlindAmounts) < MinOutputs || len(t.BlindAmounts) > MaxOutputs {
+		return Bpp{}, fmt.Errorf("bulletproof+ requires %d to %d outputs, got %d", MinOutputs, MaxOutputs, len(t.BlindAmounts))
+	}
+
 	// Bulletproof Plus для доказательства, что суммы выходов положительные
 	// без раскрытия самих сумм
 	amounts := []uint64{}
 	for _, val := range t.BlindAmounts {
-		amounts = append(amounts, val)
+		amounts = append(amounts, uint64(val))
 	}
 
 	bpp, err := createBulletproofPlus(amounts, t.BlindScalars)
@@ -640,7 +644,10 @@ func (t *Transaction) calculatePseudoOuts() ([]Hash, error) {
 		randomMask := util.RandomScalar()
 		t.InputScalars = append(t.InputScalars, randomMask.KeyToScalar())
 		sumpouts.Add(sumpouts, randomMask.KeyToScalar())
-		amountAtomic := util.XmrToAtomic(t.PInputs[i]["amount"].(float64), 1e12)
+		amountAtomic, err := prmAmount(t.PInputs[i])
+		if err != nil {
+			return []Hash{}, err
+		}
 		pseudoOut, err := CalcCommitment(amountAtomic, randomMask.ToBytes())
 		if err != nil {
 			return []Hash{}, fmt.Errorf("Error of calc commitment: %w", err)
@@ -650,7 +657,10 @@ func (t *Transaction) calculatePseudoOuts() ([]Hash, error) {
 	}
 
 	lastI := len(pseudoOuts) - 1
-	amountAtomic := util.XmrToAtomic(t.PInputs[lastI]["amount"].(float64), 1e12)
+	amountAtomic, err := prmAmount(t.PInputs[lastI])
+	if err != nil {
+		return []Hash{}, err
+	}
 
 	sumouts, err := CalcScalars(t.BlindScalars)
 	if err != nil {
@@ -669,3 +679,416 @@ func (t *Transaction) calculatePseudoOuts() ([]Hash, error) {
 
 	return pseudoOuts, nil
 }
+
+// BppProof связывает Bulletproof+ доказательство с коммитментами выходов,
+// которые оно покрывает (outPk в том виде, в каком они лежат в транзакции)
+type BppProof struct {
+	Proof *Bpp
+	OutPk []Hash
+}
+
+// bppProofData содержит восстановленные челленджи одного доказательства
+type bppProofData struct {
+	y, z, e    util.Key
+	challenges []util.Key
+	logM       int
+}
+
+var (
+	bppGeneratorsOnce sync.Once
+	bppGenerators     *Exponent
+)
+
+// getBppGenerators возвращает общие для всех проверок точки Gi/Hi
+func getBppGenerators() *Exponent {
+	bppGeneratorsOnce.Do(func() {
+		bppGenerators = initExponents(maxN, maxM)
+	})
+	return bppGenerators
+}
+
+// VerifyBulletproofPlus проверяет одно доказательство для коммитментов outPk
+func VerifyBulletproofPlus(proof Bpp, outPk []Hash) error {
+	return VerifyBulletproofPlusBatch([]BppProof{{Proof: &proof, OutPk: outPk}})
+}
+
+// VerifyBulletproofPlusBatch проверяет сразу несколько доказательств одной
+// multiexp операцией. Каждое доказательство умножается на случайный вес,
+// общие точки (G, H, Gi, Hi) входят в сумму только один раз.
+func VerifyBulletproofPlusBatch(proofs []BppProof) error {
+	if len(proofs) == 0 {
+		return nil
+	}
+
+	const (
+		logN = 6
+		N    = 1 << logN
+	)
+
+	maxLength := 0
+	proofData := make([]bppProofData, len(proofs))
+
+	for k, p := range proofs {
+		if p.Proof == nil {
+			return fmt.Errorf("proof %d: empty proof", k)
+		}
+		proof := p.Proof
+		pd := &proofData[k]
+
+		// Sanity checks
+		for _, sc := range []Hash{proof.R1, proof.S1, proof.D1} {
+			key := util.Key(sc)
+			if !util.ScValid(&key) {
+				return fmt.Errorf("proof %d: input scalar not in range", k)
+			}
+		}
+		if len(p.OutPk) < 1 {
+			return fmt.Errorf("proof %d: V does not have at least one element", k)
+		}
+		if len(proof.L) != len(proof.R) {
+			return fmt.Errorf("proof %d: mismatched L and R sizes", k)
+		}
+		if len(proof.L) == 0 {
+			return fmt.Errorf("proof %d: empty proof", k)
+		}
+
+		// V = outPk * INV_EIGHT
+		var buf bytes.Buffer
+		for _, outPk := range p.OutPk {
+			pk := util.Key(outPk)
+			v := util.ScalarMult(&util.INV_EIGHT, &pk)
+			buf.Write(v[:])
+		}
+
+		// Reconstruct the challenges
+		transcript := util.INITIAL_TRANSCRIPT
+		util.TranscriptUpdate(&transcript, util.HashToScalar(buf.Bytes()).ToBytes2())
+		pd.y = util.TranscriptUpdate(&transcript, proof.A[:])
+		if pd.y == util.Zero {
+			return fmt.Errorf("proof %d: y == 0", k)
+		}
+		transcript = *util.HashToScalar(pd.y.ToBytes2())
+		pd.z = transcript
+		if pd.z == util.Zero {
+			return fmt.Errorf("proof %d: z == 0", k)
+		}
+
+		// Determine the number of inner-product rounds based on proof size
+		M := 1
+		for M <= maxM && M < len(p.OutPk) {
+			pd.logM++
+			M = 1 << pd.logM
+		}
+		if M > maxM {
+			return fmt.Errorf("proof %d: too many outputs: %d", k, len(p.OutPk))
+		}
+		if len(proof.L) != logN+pd.logM {
+			return fmt.Errorf("proof %d: proof is not the expected size", k)
+		}
+
+		rounds := pd.logM + logN
+		pd.challenges = make([]util.Key, rounds)
+		for j := 0; j < rounds; j++ {
+			var lr bytes.Buffer
+			lr.Write(proof.L[j][:])
+			lr.Write(proof.R[j][:])
+			pd.challenges[j] = util.TranscriptUpdate(&transcript, lr.Bytes())
+			if pd.challenges[j] == util.Zero {
+				return fmt.Errorf("proof %d: challenges[%d] == 0", k, j)
+			}
+		}
+
+		// Final challenge
+		var ab bytes.Buffer
+		ab.Write(proof.A1[:])
+		ab.Write(proof.B[:])
+		pd.e = util.TranscriptUpdate(&transcript, ab.Bytes())
+		if pd.e == util.Zero {
+			return fmt.Errorf("proof %d: e == 0", k)
+		}
+
+		maxLength = max(maxLength, len(proof.L))
+	}
+
+	maxMN := 1 << maxLength
+	exponent := getBppGenerators()
+	if maxMN > len(exponent.Gi_p3) {
+		return fmt.Errorf("at least one proof is too large")
+	}
+
+	var temp, temp2 util.Key
+
+	G_scalar := util.Zero
+	H_scalar := util.Zero
+	Gi_scalars := make([]util.Key, maxMN)
+	Hi_scalars := make([]util.Key, maxMN)
+
+	multiexpData := make([]MultiexpData, 0, 2*maxMN+len(proofs)*(2*maxLength+3+maxM)+2)
+
+	for k, p := range proofs {
+		proof := p.Proof
+		pd := &proofData[k]
+
+		M := 1 << pd.logM
+		MN := M * N
+
+		// Random weighting factor must be nonzero
+		weight := util.Zero
+		for weight == util.Zero {
+			weight = *util.RandomScalar()
+		}
+
+		// Rescale previously offset proof elements
+		proof8V := make([]*edwards25519.Point, len(p.OutPk))
+		for i, outPk := range p.OutPk {
+			// V = outPk * INV_EIGHT, затем 8 * V
+			if _, err := new(edwards25519.Point).SetBytes(outPk[:]); err != nil {
+				return fmt.Errorf("proof %d: invalid commitment %d: %w", k, i, err)
+			}
+			pk := util.Key(outPk)
+			v, err := bppPoint8(Hash(util.ScalarMult(&util.INV_EIGHT, &pk)))
+			if err != nil {
+				return fmt.Errorf("proof %d: invalid commitment %d: %w", k, i, err)
+			}
+			proof8V[i] = v
+		}
+
+		proof8L := make([]*edwards25519.Point, len(proof.L))
+		proof8R := make([]*edwards25519.Point, len(proof.R))
+		for i := range proof.L {
+			l, err := new(edwards25519.Point).SetBytes(proof.L[i][:])
+			if err != nil {
+				return fmt.Errorf("proof %d: invalid L[%d]: %w", k, i, err)
+			}
+			r, err := new(edwards25519.Point).SetBytes(proof.R[i][:])
+			if err != nil {
+				return fmt.Errorf("proof %d: invalid R[%d]: %w", k, i, err)
+			}
+			proof8L[i] = new(edwards25519.Point).MultByCofactor(l)
+			proof8R[i] = new(edwards25519.Point).MultByCofactor(r)
+		}
+
+		proof8A, err := bppPoint8(proof.A)
+		if err != nil {
+			return fmt.Errorf("proof %d: invalid A: %w", k, err)
+		}
+		proof8A1, err := bppPoint8(proof.A1)
+		if err != nil {
+			return fmt.Errorf("proof %d: invalid A1: %w", k, err)
+		}
+		proof8B, err := bppPoint8(proof.B)
+		if err != nil {
+			return fmt.Errorf("proof %d: invalid B: %w", k, err)
+		}
+
+		// Compute necessary powers of the y-challenge
+		y_MN := pd.y
+		for tempMN := MN; tempMN > 1; tempMN /= 2 {
+			util.ScMul(&y_MN, y_MN, y_MN)
+		}
+		var y_MN_1 util.Key
+		util.ScMul(&y_MN_1, y_MN, pd.y)
+
+		// V_j: -e**2 * z**(2*j+2) * y**(MN+1) * weight
+		var e_squared, z_squared util.Key
+		util.ScMul(&e_squared, pd.e, pd.e)
+		util.ScMul(&z_squared, pd.z, pd.z)
+
+		util.ScSub(&temp, &util.Zero, &e_squared)
+		util.ScMul(&temp, temp, y_MN_1)
+		util.ScMul(&temp, temp, weight)
+		for j := range proof8V {
+			util.ScMul(&temp, temp, z_squared)
+			multiexpData = append(multiexpData, MultiexpData{Scalar: temp, Point: proof8V[j]})
+		}
+
+		// B: -weight
+		util.ScMul(&temp, util.MINUS_ONE, weight)
+		multiexpData = append(multiexpData, MultiexpData{Scalar: temp, Point: proof8B})
+
+		// A1: -weight*e
+		util.ScMul(&temp, temp, pd.e)
+		multiexpData = append(multiexpData, MultiexpData{Scalar: temp, Point: proof8A1})
+
+		// A: -weight*e*e
+		var minus_weight_e_squared util.Key
+		util.ScMul(&minus_weight_e_squared, temp, pd.e)
+		multiexpData = append(multiexpData, MultiexpData{Scalar: minus_weight_e_squared, Point: proof8A})
+
+		// G: weight*d1
+		d1 := util.Key(proof.D1)
+		util.ScMulAdd(&G_scalar, &weight, &d1, &G_scalar)
+
+		// Windowed vector d[j*N+i] = z**(2*(j+1)) * 2**i
+		d := createWindowedVector(z_squared, N, M)
+
+		// sum(d) = (2**64 - 1) * (z**2 + z**4 + ... + z**(2M))
+		sum_d := util.Zero
+		zPow := util.ONE
+		for j := 0; j < M; j++ {
+			util.ScMul(&zPow, zPow, z_squared)
+			util.ScAdd(&sum_d, &sum_d, &zPow)
+		}
+		util.ScMul(&sum_d, sum_d, twoSixtyFourMinusOne())
+
+		// sum(y) = y + y**2 + ... + y**MN
+		sum_y := util.Zero
+		yPow := util.ONE
+		for i := 0; i < MN; i++ {
+			util.ScMul(&yPow, yPow, pd.y)
+			util.ScAdd(&sum_y, &sum_y, &yPow)
+		}
+
+		// H: weight*( r1*y*s1 + e**2*( y**(MN+1)*z*sum(d) + (z**2-z)*sum(y) ) )
+		r1 := util.Key(proof.R1)
+		s1 := util.Key(proof.S1)
+		util.ScSub(&temp, &z_squared, &pd.z)
+		util.ScMul(&temp, temp, sum_y)
+
+		util.ScMul(&temp2, y_MN_1, pd.z)
+		util.ScMul(&temp2, temp2, sum_d)
+		util.ScAdd(&temp, &temp, &temp2)
+		util.ScMul(&temp, temp, e_squared)
+		util.ScMul(&temp2, r1, pd.y)
+		util.ScMul(&temp2, temp2, s1)
+		util.ScAdd(&temp, &temp, &temp2)
+		util.ScMulAdd(&H_scalar, &temp, &weight, &H_scalar)
+
+		rounds := pd.logM + logN
+
+		// Inverses of challenges and y
+		challengesInv := make([]util.Key, rounds)
+		for j := 0; j < rounds; j++ {
+			challengesInv[j].FromScalar(new(edwards25519.Scalar).Invert(pd.challenges[j].KeyToScalar()))
+		}
+		var yinv util.Key
+		yinv.FromScalar(new(edwards25519.Scalar).Invert(pd.y.KeyToScalar()))
+
+		// Compute challenge products
+		challengesCache := make([]util.Key, 1<<rounds)
+		challengesCache[0] = challengesInv[0]
+		challengesCache[1] = pd.challenges[0]
+		for j := 1; j < rounds; j++ {
+			slots := 1 << (j + 1)
+			for s := slots - 1; s > 0; s -= 2 {
+				util.ScMul(&challengesCache[s], challengesCache[s/2], pd.challenges[j])
+				util.ScMul(&challengesCache[s-1], challengesCache[s/2], challengesInv[j])
+			}
+		}
+
+		// Gi and Hi
+		var e_r1_w_y, e_s1_w, e_squared_z_w, minus_e_squared_z_w, minus_e_squared_w_y util.Key
+		util.ScMul(&e_r1_w_y, pd.e, r1)
+		util.ScMul(&e_r1_w_y, e_r1_w_y, weight)
+		util.ScMul(&e_s1_w, pd.e, s1)
+		util.ScMul(&e_s1_w, e_s1_w, weight)
+		util.ScMul(&e_squared_z_w, e_squared, pd.z)
+		util.ScMul(&e_squared_z_w, e_squared_z_w, weight)
+		util.ScSub(&minus_e_squared_z_w, &util.Zero, &e_squared_z_w)
+		util.ScSub(&minus_e_squared_w_y, &util.Zero, &e_squared)
+		util.ScMul(&minus_e_squared_w_y, minus_e_squared_w_y, weight)
+		util.ScMul(&minus_e_squared_w_y, minus_e_squared_w_y, y_MN)
+
+		for i := 0; i < MN; i++ {
+			var g_scalar, h_scalar util.Key
+
+			// Use the binary decomposition of the index
+			util.ScMulAdd(&g_scalar, &e_r1_w_y, &challengesCache[i], &e_squared_z_w)
+			util.ScMulAdd(&h_scalar, &e_s1_w, &challengesCache[(^i)&(MN-1)], &minus_e_squared_z_w)
+
+			// Complete the scalar derivation
+			util.ScAdd(&Gi_scalars[i], &Gi_scalars[i], &g_scalar)
+			util.ScMulAdd(&h_scalar, &minus_e_squared_w_y, &d[i], &h_scalar)
+			util.ScAdd(&Hi_scalars[i], &Hi_scalars[i], &h_scalar)
+
+			// Update iterated values
+			util.ScMul(&e_r1_w_y, e_r1_w_y, yinv)
+			util.ScMul(&minus_e_squared_w_y, minus_e_squared_w_y, yinv)
+		}
+
+		// L_j: -weight*e*e*challenges[j]**2
+		// R_j: -weight*e*e*challenges[j]**(-2)
+		for j := 0; j < rounds; j++ {
+			util.ScMul(&temp, pd.challenges[j], pd.challenges[j])
+			util.ScMul(&temp, temp, minus_weight_e_squared)
+			multiexpData = append(multiexpData, MultiexpData{Scalar: temp, Point: proof8L[j]})
+
+			util.ScMul(&temp, challengesInv[j], challengesInv[j])
+			util.ScMul(&temp, temp, minus_weight_e_squared)
+			multiexpData = append(multiexpData, MultiexpData{Scalar: temp, Point: proof8R[j]})
+		}
+	}
+
+	// Verify all proofs in the weighted batch
+	multiexpData = append(multiexpData, MultiexpData{Scalar: G_scalar, Point: edwards25519.NewGeneratorPoint()})
+	multiexpData = append(multiexpData, MultiexpData{Scalar: H_scalar, Point: getH()})
+	for i := 0; i < maxMN; i++ {
+		multiexpData = append(multiexpData, MultiexpData{Scalar: Gi_scalars[i], Point: exponent.Gi_p3[i]})
+		multiexpData = append(multiexpData, MultiexpData{Scalar: Hi_scalars[i], Point: exponent.Hi_p3[i]})
+	}
+
+	result := multiexp(multiexpData)
+	if result != util.Key(edwards25519.NewIdentityPoint().Bytes()) {
+		return fmt.Errorf("bulletproof+ verification failed")
+	}
+
+	return nil
+}
+
+// BulletproofPlusProofs возвращает доказательства транзакции вместе с
+// коммитментами outPk, которые они покрывают
+func (t *Transaction) BulletproofPlusProofs() ([]BppProof, error) {
+	if t.RctSignature == nil || t.RctSigPrunable == nil {
+		return nil, fmt.Errorf("transaction has no RingCT signature")
+	}
+	if t.RctSignature.Type != uint64(util.RCTTypeBulletproofPlus) {
+		return nil, fmt.Errorf("unsupported RingCT type: %d", t.RctSignature.Type)
+	}
+	if len(t.RctSigPrunable.Bpp) != 1 {
+		return nil, fmt.Errorf("unsupported bulletproof+ count: %d", len(t.RctSigPrunable.Bpp))
+	}
+	if len(t.RctSignature.OutPk) != len(t.Outputs) {
+		return nil, fmt.Errorf("outPk count mismatch: got %d, expected %d", len(t.RctSignature.OutPk), len(t.Outputs))
+	}
+
+	return []BppProof{{
+		Proof: &t.RctSigPrunable.Bpp[0],
+		OutPk: t.RctSignature.OutPk,
+	}}, nil
+}
+
+// VerifyBulletproofPlus проверяет range proof транзакции
+func (t *Transaction) VerifyBulletproofPlus() error {
+	return VerifyTransactionsBulletproofPlus([]*Transaction{t})
+}
+
+// VerifyTransactionsBulletproofPlus проверяет range proofs всех транзакций
+// (например, всего блока) одной batch-проверкой
+func VerifyTransactionsBulletproofPlus(txs []*Transaction) error {
+	proofs := []BppProof{}
+	for i, tx := range txs {
+		txProofs, err := tx.BulletproofPlusProofs()
+		if err != nil {
+			return fmt.Errorf("tx %d: %w", i, err)
+		}
+		proofs = append(proofs, txProofs...)
+	}
+
+	return VerifyBulletproofPlusBatch(proofs)
+}
+
+// bppPoint8 декодирует точку и умножает её на 8
+func bppPoint8(h Hash) (*edwards25519.Point, error) {
+	point, err := new(edwards25519.Point).SetBytes(h[:])
+	if err != nil {
+		return nil, err
+	}
+	return new(edwards25519.Point).MultByCofactor(point), nil
+}
+
+// twoSixtyFourMinusOne возвращает скаляр 2**64 - 1
+func twoSixtyFourMinusOne() util.Key {
+	var k util.Key
+	binary.LittleEndian.PutUint64(k[:8], ^uint64(0))
+	return k
+}
@@ -0,0 +1,394 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+// TxDestination - получатель и сумма
+type TxDestination struct {
+	Address string      `json:"address"`
+	Amount  util.Amount `json:"amount"`
+}
+
+// TxBuilder собирает транзакцию из типизированных параметров и сам вызывает
+// шаги NewEmptyTransaction ... CalcHash в правильном порядке.
+// Если комиссия не задана через SetFee, она вычисляется по оценке веса
+// и get_fee_estimate; остаток уходит на сдачу (по умолчанию - основной адрес).
+type TxBuilder struct {
+	privSpendKey util.Key
+	privViewKey  util.Key
+	pubSpendKey  util.Key
+	network      util.Network
+
+	inputs        []OwnedOutput
+	destinations  []TxDestination
+	changeAddress string
+	fee           util.Amount
+	feeSet        bool
+	priority      FeePriority
+}
+
+// NewTxBuilder создаёт builder для кошелька с указанными секретными ключами
+func NewTxBuilder(privSpendKey, privViewKey util.Key) *TxBuilder {
+	return &TxBuilder{
+		privSpendKey: privSpendKey,
+		privViewKey:  privViewKey,
+		pubSpendKey:  *privSpendKey.PubKey(),
+		priority:     FeePriorityNormal,
+	}
+}
+
+// AddInput добавляет тратимый выход, найденный ScanOutputs
+func (b *TxBuilder) AddInput(output OwnedOutput) error {
+	if output.Amount == 0 {
+		return fmt.Errorf("input %x:%d has zero amount", output.TxHash, output.Index)
+	}
+
+	for _, in := range b.inputs {
+		if in.TxHash == output.TxHash && in.Index == output.Index {
+			return fmt.Errorf("input %x:%d already added", output.TxHash, output.Index)
+		}
+	}
+
+	// Проверяем, что выход действительно можно потратить нашими ключами
+	txPubKey := util.Key(output.TxPubKey)
+	derivation, ok := util.GenerateKeyDerivation(&txPubKey, &b.privViewKey)
+	if !ok {
+		return fmt.Errorf("input %x:%d: invalid tx public key", output.TxHash, output.Index)
+	}
+
+	spendKey := util.SubaddressSpendKey(&b.privViewKey, &b.pubSpendKey, output.Subaddress)
+	oneTimeKey, ok := util.DerivePublicKey(&derivation, output.Index, &spendKey)
+	if !ok || Hash(oneTimeKey) != output.OneTimeKey {
+		return fmt.Errorf("input %x:%d does not belong to this wallet", output.TxHash, output.Index)
+	}
+
+	b.inputs = append(b.inputs, output)
+	return nil
+}
+
+// AddDestination добавляет получателя
+func (b *TxBuilder) AddDestination(address string, amount util.Amount) error {
+	if amount == 0 {
+		return fmt.Errorf("destination %s: amount must be positive", address)
+	}
+	if _, err := util.ParseAddressForNetwork(address, b.network); err != nil {
+		return fmt.Errorf("destination %s: %w", address, err)
+	}
+
+	b.destinations = append(b.destinations, TxDestination{Address: address, Amount: amount})
+	return nil
+}
+
+// SetChange задаёт адрес для сдачи; по умолчанию используется основной адрес кошелька
+func (b *TxBuilder) SetChange(address string) error {
+	if _, err := util.ParseAddressForNetwork(address, b.network); err != nil {
+		return fmt.Errorf("change address %s: %w", address, err)
+	}
+
+	b.changeAddress = address
+	return nil
+}
+
+// SetNetwork задаёт сеть кошелька (по умолчанию mainnet); адреса получателей
+// и сдачи должны принадлежать этой сети
+func (b *TxBuilder) SetNetwork(network util.Network) error {
+	for _, dest := range b.destinations {
+		if _, err := util.ParseAddressForNetwork(dest.Address, network); err != nil {
+			return fmt.Errorf("destination %s: %w", dest.Address, err)
+		}
+	}
+	if b.changeAddress != "" {
+		if _, err := util.ParseAddressForNetwork(b.changeAddress, network); err != nil {
+			return fmt.Errorf("change address %s: %w", b.changeAddress, err)
+		}
+	}
+
+	b.network = network
+	return nil
+}
+
+// SetFee задаёт комиссию транзакции вместо автоматического расчёта
+func (b *TxBuilder) SetFee(fee util.Amount) {
+	b.fee = fee
+	b.feeSet = true
+}
+
+// SetPriority задаёт уровень комиссии для автоматического расчёта
+func (b *TxBuilder) SetPriority(priority FeePriority) {
+	b.priority = priority
+}
+
+// ChangeAddress возвращает адрес сдачи с учётом значения по умолчанию
+func (b *TxBuilder) ChangeAddress() string {
+	if b.changeAddress != "" {
+		return b.changeAddress
+	}
+	return util.SubaddressAddress(b.network, &b.privViewKey, &b.pubSpendKey, util.SubaddressIndex{}).String()
+}
+
+// EstimateFee вычисляет комиссию по оценочному весу транзакции
+// с учётом выхода для сдачи
+func (b *TxBuilder) EstimateFee(rpcCli RPCClient) (util.Amount, error) {
+	estimate, err := rpcCli.GetFeeEstimate()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee estimate: %w", err)
+	}
+
+	weight := EstimateTxWeight(len(b.inputs), b.outputCount(), b.extraSize())
+	return estimate.FeeForWeight(weight, b.priority)
+}
+
+// outputCount - количество выходов с учётом сдачи; при 16 получателях
+// транзакция собирается только без сдачи
+func (b *TxBuilder) outputCount() int {
+	return min(max(len(b.destinations)+1, MinOutputs), MaxOutputs)
+}
+
+// extraSize оценивает размер extra с учётом дополнительных tx pubkey
+func (b *TxBuilder) extraSize() int {
+	addresses := make([]string, 0, len(b.destinations))
+	for _, dest := range b.destinations {
+		addresses = append(addresses, dest.Address)
+	}
+	if NeedAdditionalTxKeys(addresses) {
+		return estimatedExtraSize(b.outputCount())
+	}
+	return estimatedExtraSize(0)
+}
+
+// SelectInputs выбирает входы из outputs стратегией strategy для суммы всех получателей
+// с учётом комиссии и добавляет их в builder. Получатели должны быть добавлены заранее.
+func (b *TxBuilder) SelectInputs(rpcCli RPCClient, outputs []OwnedOutput, strategy SelectionStrategy) (*CoinSelection, error) {
+	if len(b.destinations) == 0 {
+		return nil, errors.New("no destinations")
+	}
+	if len(b.inputs) >= MaxInputs {
+		return nil, ErrTooManyInputs
+	}
+
+	var target util.Amount
+	for _, dest := range b.destinations {
+		target += dest.Amount
+	}
+
+	_, height, err := rpcCli.GetHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get height: %w", err)
+	}
+
+	selector := &CoinSelector{
+		Strategy:     strategy,
+		ChainHeight:  height,
+		Destinations: len(b.destinations),
+		ExtraSize:    b.extraSize(),
+		Priority:     b.priority,
+		MaxInputs:    MaxInputs - len(b.inputs),
+	}
+
+	if !b.feeSet {
+		if selector.FeeEstimate, err = rpcCli.GetFeeEstimate(); err != nil {
+			return nil, fmt.Errorf("failed to get fee estimate: %w", err)
+		}
+	} else {
+		target += b.fee
+	}
+
+	selection, err := selector.Select(outputs, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range selection.Inputs {
+		if err := b.AddInput(o); err != nil {
+			return nil, err
+		}
+	}
+	return selection, nil
+}
+
+// Build проверяет параметры, запрашивает у демона данные для колец
+// и возвращает подписанную транзакцию. Если rpcCli реализует RPCClientContext,
+// отмена ctx прерывает и выполняющиеся запросы к демону.
+func (b *TxBuilder) Build(ctx context.Context, rpcCli RPCClient) (*Transaction, error) {
+	rpcCli = WithContext(ctx, rpcCli)
+
+	fee := b.fee
+	if !b.feeSet && len(b.inputs) > 0 && len(b.destinations) > 0 {
+		var err error
+		if fee, err = b.EstimateFee(rpcCli); err != nil {
+			return nil, err
+		}
+	}
+
+	change, err := b.validate(fee)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tx := NewEmptyTransaction()
+
+	for _, in := range b.inputs {
+		tx.WriteInput(b.inputPrm(in))
+	}
+
+	for _, dest := range b.destinations {
+		tx.WriteOutput(TxPrm{
+			"address":        dest.Address,
+			"amount":         dest.Amount,
+			"change_address": false,
+		})
+	}
+
+	// Монеро требует минимум два выхода, поэтому при одном получателе
+	// добавляется сдача, даже нулевая
+	if change > 0 || len(b.destinations) < MinOutputs {
+		tx.WriteOutput(b.changePrm(change))
+	}
+
+	if err := tx.CalcFeeDifference(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.CalcExtra(); err != nil {
+		return nil, fmt.Errorf("failed to calculate extra: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, height, err := rpcCli.GetHeight()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get height: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.CalcInputs(rpcCli, height); err != nil {
+		return nil, fmt.Errorf("failed to calculate inputs: %w", err)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.CalcOutputs(); err != nil {
+		return nil, fmt.Errorf("failed to calculate outputs: %w", err)
+	}
+
+	if err := tx.SignTransaction(); err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	tx.CalcHash()
+
+	if violations := ValidateTransaction(tx, nil); len(violations) > 0 {
+		return nil, violations
+	}
+
+	return tx, nil
+}
+
+// validate проверяет параметры и возвращает сумму сдачи
+func (b *TxBuilder) validate(fee util.Amount) (util.Amount, error) {
+	errs := []error{}
+
+	if len(b.inputs) == 0 {
+		errs = append(errs, errors.New("no inputs"))
+	}
+	if len(b.destinations) == 0 {
+		errs = append(errs, errors.New("no destinations"))
+	}
+	if len(b.inputs) > MaxInputs {
+		errs = append(errs, fmt.Errorf("too many inputs: %d, at most %d allowed", len(b.inputs), MaxInputs))
+	}
+
+	var inputSum, outputSum util.Amount
+	var err error
+	for _, in := range b.inputs {
+		if inputSum, err = inputSum.Add(in.Amount); err != nil {
+			errs = append(errs, fmt.Errorf("inputs: %w", err))
+		}
+	}
+	for _, dest := range b.destinations {
+		if outputSum, err = outputSum.Add(dest.Amount); err != nil {
+			errs = append(errs, fmt.Errorf("destinations: %w", err))
+		}
+	}
+	if outputSum, err = outputSum.Add(fee); err != nil {
+		errs = append(errs, fmt.Errorf("fee: %w", err))
+	}
+
+	change, err := inputSum.Sub(outputSum)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("insufficient funds: inputs %s < destinations + fee %s", inputSum, outputSum))
+	}
+
+	// Выход сдачи добавляется только при ненулевой сдаче или одном получателе (как в Build)
+	outputs := len(b.destinations)
+	if change > 0 || outputs < MinOutputs {
+		outputs++
+	}
+	if outputs > MaxOutputs {
+		errs = append(errs, fmt.Errorf("too many destinations: %d with change output, at most %d outputs allowed", len(b.destinations), MaxOutputs))
+	}
+
+	if len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	return change, nil
+}
+
+func (b *TxBuilder) inputPrm(in OwnedOutput) TxPrm {
+	// Секретный ключ траты субадреса: b + m
+	spendSecret := b.privSpendKey
+	if !in.Subaddress.IsPrimary() {
+		m := util.SubaddressSecretKey(&b.privViewKey, in.Subaddress)
+		util.ScAdd(&spendSecret, &b.privSpendKey, &m)
+	}
+
+	return TxPrm{
+		"txId":            fmt.Sprintf("%x", in.TxHash),
+		"vout":            in.Index,
+		"amount":          in.Amount,
+		"address":         util.SubaddressAddress(b.network, &b.privViewKey, &b.pubSpendKey, in.Subaddress).String(),
+		"txPubKey":        in.TxPubKey,
+		"privateViewKey":  b.privViewKey,
+		"privateSpendKey": spendSecret,
+	}
+}
+
+func (b *TxBuilder) changePrm(change util.Amount) TxPrm {
+	address := b.ChangeAddress()
+	return TxPrm{
+		"address":        address,
+		"amount":         change,
+		"change_address": b.isOwnAddress(address),
+		"privateViewKey": b.privViewKey,
+	}
+}
+
+// isOwnAddress проверяет, что адрес принадлежит кошельку (основной или субадрес)
+func (b *TxBuilder) isOwnAddress(address string) bool {
+	pubSpend, pubView, err := util.DecodeAddress(address)
+	if err != nil {
+		return false
+	}
+
+	spend := util.Key(pubSpend)
+	if util.Key(pubView) == *b.privViewKey.PubKey() && spend == b.pubSpendKey {
+		return true
+	}
+	return util.ScalarMult(&b.privViewKey, &spend) == util.Key(pubView)
+}
@@ -368,3 +368,197 @@ func cnFastHashKeyV(kv []util.Key) util.Key {
 	hash := util.Keccak256(buf.Bytes())
 	return util.Key(hash)
 }
+
+// VerifyCLSAG checks a single CLSAG ring signature.
+// P are the ring one-time keys, C_nonzero their commitments, C_offset the
+// pseudo-out commitment of the input and keyImage the spent key image.
+func VerifyCLSAG(message Hash, sig CLSAG, P []util.Key, C_nonzero []util.Key, C_offset util.Key, keyImage Hash) error {
+	n := len(P) // ring size
+
+	// Проверки размеров
+	if n == 0 {
+		return fmt.Errorf("Empty ring")
+	}
+	if n != len(C_nonzero) {
+		return fmt.Errorf("Signing and commitment key vector sizes must match! P:%d C_nonzero:%d", len(P), len(C_nonzero))
+	}
+	if n != len(sig.S) {
+		return fmt.Errorf("Signature scalar vector size mismatch! P:%d s:%d", len(P), len(sig.S))
+	}
+
+	// Все скаляры должны быть приведены по модулю l
+	c1 := util.Key(sig.C1)
+	if !util.ScValid(&c1) {
+		return fmt.Errorf("Bad signature scalar c1")
+	}
+	for i := range sig.S {
+		s := util.Key(sig.S[i])
+		if !util.ScValid(&s) {
+			return fmt.Errorf("Bad signature scalar s[%d]", i)
+		}
+	}
+
+	// util.Key images
+	I := util.Key(keyImage)
+	if I == util.Identity {
+		return fmt.Errorf("Bad key image")
+	}
+	var I_p3 util.ExtendedGroupElement
+	if !I_p3.FromBytes(&I) {
+		return fmt.Errorf("Bad key image")
+	}
+
+	// D8 = 8 * sig.D (в подписи хранится D * INV_EIGHT)
+	sigD := util.Key(sig.D)
+	var D_check util.ExtendedGroupElement
+	if !D_check.FromBytes(&sigD) {
+		return fmt.Errorf("Bad auxiliary key image")
+	}
+	eight := util.Key{8}
+	D8 := util.ScalarMult(&eight, &sigD)
+	if D8 == util.Identity {
+		return fmt.Errorf("Bad auxiliary key image")
+	}
+
+	var I_precomp, D_precomp util.CachedGroupElement
+	var D_p3 util.ExtendedGroupElement
+	D_p3.FromBytes(&D8)
+	I_p3.ToCached(&I_precomp)
+	D_p3.ToCached(&D_precomp)
+
+	// C[i] = C_nonzero[i] - C_offset
+	C := make([]util.Key, n)
+	for i := 0; i < n; i++ {
+		util.SubKeys(&C[i], &C_nonzero[i], &C_offset)
+	}
+
+	// Aggregation hashes
+	mu_P_to_hash := make([]util.Key, 2*n+4)
+	mu_C_to_hash := make([]util.Key, 2*n+4)
+
+	// Domain separators
+	HASH_KEY_CLSAG_AGG_0 := "CLSAG_agg_0"
+	HASH_KEY_CLSAG_AGG_1 := "CLSAG_agg_1"
+	copy(mu_P_to_hash[0][:], []byte(HASH_KEY_CLSAG_AGG_0))
+	copy(mu_C_to_hash[0][:], []byte(HASH_KEY_CLSAG_AGG_1))
+
+	for i := 1; i < n+1; i++ {
+		mu_P_to_hash[i] = P[i-1]
+		mu_C_to_hash[i] = P[i-1]
+	}
+
+	for i := n + 1; i < 2*n+1; i++ {
+		mu_P_to_hash[i] = C_nonzero[i-n-1]
+		mu_C_to_hash[i] = C_nonzero[i-n-1]
+	}
+
+	mu_P_to_hash[2*n+1] = I
+	mu_P_to_hash[2*n+2] = sigD
+	mu_P_to_hash[2*n+3] = C_offset
+	mu_C_to_hash[2*n+1] = I
+	mu_C_to_hash[2*n+2] = sigD
+	mu_C_to_hash[2*n+3] = C_offset
+
+	mu_P := hashToScalar(mu_P_to_hash)
+	mu_C := hashToScalar(mu_C_to_hash)
+
+	// Round hash
+	c_to_hash := make([]util.Key, 2*n+5)
+
+	HASH_KEY_CLSAG_ROUND := "CLSAG_round"
+	copy(c_to_hash[0][:], []byte(HASH_KEY_CLSAG_ROUND))
+
+	for i := 1; i < n+1; i++ {
+		c_to_hash[i] = P[i-1]
+		c_to_hash[i+n] = C_nonzero[i-1]
+	}
+	c_to_hash[2*n+1] = C_offset
+	c_to_hash[2*n+2] = util.Key(message)
+
+	c := c1
+	var L, R, c_p, c_c util.Key
+	for i := 0; i < n; i++ {
+		// c_p = c * mu_P, c_c = c * mu_C
+		util.ScMul(&c_p, c, mu_P)
+		util.ScMul(&c_c, c, mu_C)
+
+		var P_precomp, C_precomp util.CachedGroupElement
+		var P_p3, C_p3 util.ExtendedGroupElement
+		if !P_p3.FromBytes(&P[i]) {
+			return fmt.Errorf("Bad ring member key at index %d", i)
+		}
+		if !C_p3.FromBytes(&C[i]) {
+			return fmt.Errorf("Bad ring member commitment at index %d", i)
+		}
+		P_p3.ToCached(&P_precomp)
+		C_p3.ToCached(&C_precomp)
+
+		// L = s[i]*G + c_p*P[i] + c_c*C[i]
+		addKeysAGbBcC(&L, (*util.Key)(&sig.S[i]), &c_p, &P_precomp, &c_c, &C_precomp)
+
+		// R = s[i]*Hp(P[i]) + c_p*I + c_c*D
+		var H_precomp util.CachedGroupElement
+		var Hi_p3_2 util.Key
+		var Hi_p3_3 util.ExtendedGroupElement
+		P[i].HashToEC().ToBytes(&Hi_p3_2)
+		Hi_p3_3.FromBytes(&Hi_p3_2)
+		Hi_p3_3.ToCached(&H_precomp)
+
+		addKeysAAbBcC(&R, (*util.Key)(&sig.S[i]), &H_precomp, &c_p, &I_precomp, &c_c, &D_precomp)
+
+		c_to_hash[2*n+3] = L
+		c_to_hash[2*n+4] = R
+		c = hashToScalar(c_to_hash)
+	}
+
+	if c != c1 {
+		return fmt.Errorf("CLSAG verification failed")
+	}
+
+	return nil
+}
+
+// VerifyCLSAGs checks every ring signature of the transaction.
+// rings[i] must hold the resolved ring members of input i in key offset order
+// (see GetRingMembers).
+func (t *Transaction) VerifyCLSAGs(rings [][]Mixin) error {
+	if t.RctSignature == nil || t.RctSigPrunable == nil {
+		return fmt.Errorf("transaction has no RingCT signature")
+	}
+	if t.RctSignature.Type != uint64(util.RCTTypeBulletproofPlus) {
+		return fmt.Errorf("unsupported RingCT type: %d", t.RctSignature.Type)
+	}
+	if len(rings) != len(t.Inputs) {
+		return fmt.Errorf("rings count mismatch: got %d, expected %d", len(rings), len(t.Inputs))
+	}
+	if len(t.RctSigPrunable.CLSAGs) != len(t.Inputs) {
+		return fmt.Errorf("CLSAGs count mismatch: got %d, expected %d", len(t.RctSigPrunable.CLSAGs), len(t.Inputs))
+	}
+	if len(t.RctSigPrunable.PseudoOuts) != len(t.Inputs) {
+		return fmt.Errorf("pseudo outs count mismatch: got %d, expected %d", len(t.RctSigPrunable.PseudoOuts), len(t.Inputs))
+	}
+
+	full_message, err := GetFullMessage(util.Key(t.PrefixHash()), t.RctSignature, t.RctSigPrunable)
+	if err != nil {
+		return err
+	}
+
+	for i, input := range t.Inputs {
+		if len(rings[i]) != len(input.KeyOffsets) {
+			return fmt.Errorf("input %d: ring size mismatch: got %d, expected %d", i, len(rings[i]), len(input.KeyOffsets))
+		}
+
+		P := make([]util.Key, len(rings[i]))
+		C_nonzero := make([]util.Key, len(rings[i]))
+		for j, mixin := range rings[i] {
+			P[j] = util.Key(mixin.Dest)
+			C_nonzero[j] = util.Key(mixin.Mask)
+		}
+
+		if err := VerifyCLSAG(Hash(full_message), t.RctSigPrunable.CLSAGs[i], P, C_nonzero, util.Key(t.RctSigPrunable.PseudoOuts[i]), input.KeyImage); err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
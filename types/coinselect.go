@@ -0,0 +1,315 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+const MaxInputs = 16
+
+// SelectionStrategy определяет порядок выбора входов
+type SelectionStrategy int
+
+const (
+	// SelectMinInputs - как можно меньше входов (сначала крупные)
+	SelectMinInputs SelectionStrategy = iota
+	// SelectMinChange - набор входов с минимальной сдачей
+	SelectMinChange
+	// SelectOldestFirst - сначала самые старые выходы
+	SelectOldestFirst
+	// SelectConsolidateDust - как можно больше мелких выходов, пока это окупает комиссию
+	SelectConsolidateDust
+)
+
+func (s SelectionStrategy) String() string {
+	switch s {
+	case SelectMinInputs:
+		return "min_inputs"
+	case SelectMinChange:
+		return "min_change"
+	case SelectOldestFirst:
+		return "oldest_first"
+	case SelectConsolidateDust:
+		return "consolidate_dust"
+	default:
+		return fmt.Sprintf("strategy(%d)", int(s))
+	}
+}
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient unlocked funds")
+	ErrTooManyInputs     = errors.New("target requires more inputs than allowed")
+)
+
+// minChangeMaxSteps ограничивает перебор в SelectMinChange
+const minChangeMaxSteps = 100000
+
+// CoinSelector выбирает входы из принадлежащих кошельку выходов
+type CoinSelector struct {
+	Strategy    SelectionStrategy
+	ChainHeight uint64
+	// Destinations - количество получателей без учёта сдачи
+	Destinations int
+	// ExtraSize - оценочный размер extra; если 0, extra без дополнительных ключей
+	ExtraSize int
+	// FeeEstimate - если nil, комиссия не учитывается
+	FeeEstimate *FeeEstimate
+	Priority    FeePriority
+	// MaxInputs - если 0, используется MaxInputs
+	MaxInputs int
+	// Now - время для проверки unlock_time; если нулевое, используется time.Now()
+	Now time.Time
+}
+
+// CoinSelection - результат выбора входов
+type CoinSelection struct {
+	Inputs []OwnedOutput `json:"inputs"`
+	Total  util.Amount   `json:"total"`
+	Fee    util.Amount   `json:"fee"`
+	Change util.Amount   `json:"change"`
+}
+
+// Select выбирает входы для отправки target (без учёта комиссии)
+func (s *CoinSelector) Select(outputs []OwnedOutput, target util.Amount) (*CoinSelection, error) {
+	if target == 0 {
+		return nil, errors.New("target amount must be positive")
+	}
+
+	fees, err := s.feeTable()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	spendable := make([]OwnedOutput, 0, len(outputs))
+	var available, locked util.Amount
+	for _, o := range outputs {
+		if o.Amount == 0 {
+			continue
+		}
+		if !o.IsUnlocked(s.ChainHeight, now) {
+			locked += o.Amount
+			continue
+		}
+		spendable = append(spendable, o)
+		available += o.Amount
+	}
+
+	var selected []OwnedOutput
+	switch s.Strategy {
+	case SelectMinInputs:
+		sortByAmountDesc(spendable)
+		selected = selectGreedy(spendable, target, fees)
+	case SelectOldestFirst:
+		sort.SliceStable(spendable, func(i, j int) bool {
+			if spendable[i].BlockHeight != spendable[j].BlockHeight {
+				return spendable[i].BlockHeight < spendable[j].BlockHeight
+			}
+			return spendable[i].Amount > spendable[j].Amount
+		})
+		selected = selectGreedy(spendable, target, fees)
+	case SelectMinChange:
+		sortByAmountDesc(spendable)
+		selected = selectMinChange(spendable, target, fees)
+	case SelectConsolidateDust:
+		selected = selectDust(spendable, target, fees)
+	default:
+		return nil, fmt.Errorf("unknown selection strategy %s", s.Strategy)
+	}
+
+	if selected == nil {
+		if len(spendable) > 0 {
+			// Проверяем, хватило бы средств без ограничения на количество входов
+			sortByAmountDesc(spendable)
+			var sum util.Amount
+			for i, o := range spendable {
+				sum += o.Amount
+				if i+1 >= len(fees) && sum >= target {
+					return nil, fmt.Errorf("%w: maximum is %d", ErrTooManyInputs, len(fees)-1)
+				}
+			}
+		}
+		return nil, fmt.Errorf("%w: need %s plus fee, unlocked %s, locked %s", ErrInsufficientFunds, target, available, locked)
+	}
+
+	result := &CoinSelection{Inputs: selected, Fee: fees[len(selected)]}
+	for _, o := range selected {
+		result.Total += o.Amount
+	}
+	result.Change = result.Total - target - result.Fee
+	return result, nil
+}
+
+// feeTable возвращает комиссию для 0..MaxInputs входов
+func (s *CoinSelector) feeTable() ([]util.Amount, error) {
+	maxInputs := s.MaxInputs
+	if maxInputs <= 0 || maxInputs > MaxInputs {
+		maxInputs = MaxInputs
+	}
+
+	fees := make([]util.Amount, maxInputs+1)
+	if s.FeeEstimate == nil {
+		return fees, nil
+	}
+
+	extraSize := s.ExtraSize
+	if extraSize <= 0 {
+		extraSize = estimatedExtraSize(0)
+	}
+
+	outputs := max(s.Destinations+1, MinOutputs)
+	for n := 1; n <= maxInputs; n++ {
+		fee, err := s.FeeEstimate.FeeForWeight(EstimateTxWeight(n, outputs, extraSize), s.Priority)
+		if err != nil {
+			return nil, err
+		}
+		fees[n] = fee
+	}
+	return fees, nil
+}
+
+func sortByAmountDesc(outputs []OwnedOutput) {
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].Amount > outputs[j].Amount
+	})
+}
+
+// selectGreedy берёт выходы по порядку, пока сумма не покроет target + комиссию
+func selectGreedy(outputs []OwnedOutput, target util.Amount, fees []util.Amount) []OwnedOutput {
+	var sum util.Amount
+	for i, o := range outputs {
+		if i+1 >= len(fees) {
+			return nil
+		}
+		sum += o.Amount
+		if sum >= target+fees[i+1] {
+			return append([]OwnedOutput(nil), outputs[:i+1]...)
+		}
+	}
+	return nil
+}
+
+// selectMinChange перебирает наборы (выходы отсортированы по убыванию)
+// и возвращает набор с минимальной сдачей
+func selectMinChange(outputs []OwnedOutput, target util.Amount, fees []util.Amount) []OwnedOutput {
+	best := selectGreedy(outputs, target, fees)
+	if best == nil {
+		return nil
+	}
+
+	var bestChange util.Amount
+	for _, o := range best {
+		bestChange += o.Amount
+	}
+	bestChange -= target + fees[len(best)]
+
+	// suffix[i] - сумма outputs[i:]
+	suffix := make([]util.Amount, len(outputs)+1)
+	for i := len(outputs) - 1; i >= 0; i-- {
+		suffix[i] = suffix[i+1] + outputs[i].Amount
+	}
+
+	steps := 0
+	current := make([]int, 0, len(fees))
+	var search func(start int, sum util.Amount)
+	search = func(start int, sum util.Amount) {
+		if bestChange == 0 || steps >= minChangeMaxSteps {
+			return
+		}
+		steps++
+
+		for i := start; i < len(outputs); i++ {
+			n := len(current) + 1
+			if n >= len(fees) {
+				return
+			}
+
+			// Даже все оставшиеся выходы не покроют цель
+			if sum+suffix[i] < target+fees[n] {
+				return
+			}
+
+			newSum := sum + outputs[i].Amount
+			current = append(current, i)
+			if newSum >= target+fees[n] {
+				if change := newSum - target - fees[n]; change < bestChange {
+					bestChange = change
+					best = best[:0:0]
+					for _, j := range current {
+						best = append(best, outputs[j])
+					}
+				}
+			} else {
+				search(i+1, newSum)
+			}
+			current = current[:len(current)-1]
+		}
+	}
+	search(0, 0)
+
+	return best
+}
+
+// selectDust добавляет самые мелкие выходы, пока каждый из них окупает
+// свою долю комиссии, затем докладывает крупные до покрытия цели
+func selectDust(outputs []OwnedOutput, target util.Amount, fees []util.Amount) []OwnedOutput {
+	sorted := append([]OwnedOutput(nil), outputs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Amount < sorted[j].Amount
+	})
+
+	maxInputs := len(fees) - 1
+	used := make([]bool, len(sorted))
+	selected := make([]int, 0, maxInputs)
+	var sum util.Amount
+	for i, o := range sorted {
+		if len(selected) >= maxInputs {
+			break
+		}
+		// Каждый следующий вход должен быть больше добавляемой им комиссии
+		n := len(selected) + 1
+		if n > 1 && o.Amount <= fees[n]-fees[n-1] {
+			continue
+		}
+		selected = append(selected, i)
+		used[i] = true
+		sum += o.Amount
+	}
+
+	// Не хватает - добавляем самые крупные из оставшихся, вытесняя самые мелкие выбранные
+	large := len(sorted) - 1
+	for len(selected) == 0 || sum < target+fees[len(selected)] {
+		for large >= 0 && used[large] {
+			large--
+		}
+		if large < 0 {
+			return nil
+		}
+
+		if len(selected) < maxInputs {
+			selected = append(selected, large)
+		} else {
+			if sorted[large].Amount <= sorted[selected[0]].Amount {
+				return nil
+			}
+			sum -= sorted[selected[0]].Amount
+			selected = append(selected[1:], large)
+		}
+		used[large] = true
+		sum += sorted[large].Amount
+	}
+
+	result := make([]OwnedOutput, 0, len(selected))
+	for _, i := range selected {
+		result = append(result, sorted[i])
+	}
+	return result
+}
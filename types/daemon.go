@@ -0,0 +1,250 @@
+package types
+
+// DaemonInfo - ответ get_info
+type DaemonInfo struct {
+	Height                   uint64 `json:"height"`
+	TargetHeight             uint64 `json:"target_height"`
+	TopBlockHash             Hash   `json:"top_block_hash"`
+	Difficulty               uint64 `json:"difficulty"`
+	CumulativeDifficulty     uint64 `json:"cumulative_difficulty"`
+	TxCount                  uint64 `json:"tx_count"`
+	TxPoolSize               uint64 `json:"tx_pool_size"`
+	AltBlocksCount           uint64 `json:"alt_blocks_count"`
+	OutgoingConnectionsCount uint64 `json:"outgoing_connections_count"`
+	IncomingConnectionsCount uint64 `json:"incoming_connections_count"`
+	WhitePeerlistSize        uint64 `json:"white_peerlist_size"`
+	GreyPeerlistSize         uint64 `json:"grey_peerlist_size"`
+	BlockSizeLimit           uint64 `json:"block_size_limit"`
+	BlockWeightLimit         uint64 `json:"block_weight_limit"`
+	BlockWeightMedian        uint64 `json:"block_weight_median"`
+	DatabaseSize             uint64 `json:"database_size"`
+	StartTime                uint64 `json:"start_time"`
+	Nettype                  string `json:"nettype"`
+	Version                  string `json:"version"`
+	Synchronized             bool   `json:"synchronized"`
+	BusySyncing              bool   `json:"busy_syncing"`
+	Offline                  bool   `json:"offline"`
+	UpdateAvailable          bool   `json:"update_available"`
+	Untrusted                bool   `json:"untrusted"`
+}
+
+// BlockHeader - заголовок блока из get_block_header_* и get_last_block_header
+type BlockHeader struct {
+	MajorVersion         uint8  `json:"major_version"`
+	MinorVersion         uint8  `json:"minor_version"`
+	Timestamp            uint64 `json:"timestamp"`
+	PrevHash             Hash   `json:"prev_hash"`
+	Nonce                uint32 `json:"nonce"`
+	OrphanStatus         bool   `json:"orphan_status"`
+	Height               uint64 `json:"height"`
+	Depth                uint64 `json:"depth"`
+	Hash                 Hash   `json:"hash"`
+	Difficulty           uint64 `json:"difficulty"`
+	CumulativeDifficulty uint64 `json:"cumulative_difficulty"`
+	Reward               uint64 `json:"reward"`
+	BlockSize            uint64 `json:"block_size"`
+	BlockWeight          uint64 `json:"block_weight"`
+	LongTermWeight       uint64 `json:"long_term_weight"`
+	NumTxes              uint64 `json:"num_txes"`
+	PowHash              Hash   `json:"pow_hash"`
+	MinerTxHash          Hash   `json:"miner_tx_hash"`
+}
+
+// BlockResult - ответ get_block
+type BlockResult struct {
+	BlockHeader BlockHeader `json:"block_header"`
+	// Blob - сериализованный блок
+	Blob        []byte `json:"-"`
+	MinerTxHash Hash   `json:"miner_tx_hash"`
+	TxHashes    []Hash `json:"tx_hashes"`
+	// JSON - блок в виде JSON, как его отдаёт демон
+	JSON string `json:"json"`
+}
+
+// PoolTx - транзакция из get_transaction_pool
+type PoolTx struct {
+	Hash Hash `json:"id_hash"`
+	// Blob - сериализованная транзакция (tx_blob)
+	Blob               []byte `json:"-"`
+	BlobSize           uint64 `json:"blob_size"`
+	Weight             uint64 `json:"weight"`
+	Fee                uint64 `json:"fee"`
+	ReceiveTime        uint64 `json:"receive_time"`
+	LastRelayedTime    uint64 `json:"last_relayed_time"`
+	MaxUsedBlockHeight uint64 `json:"max_used_block_height"`
+	Relayed            bool   `json:"relayed"`
+	KeptByBlock        bool   `json:"kept_by_block"`
+	DoubleSpendSeen    bool   `json:"double_spend_seen"`
+	DoNotRelay         bool   `json:"do_not_relay"`
+}
+
+// PoolKeyImage - образ ключа, потраченный транзакциями пула
+type PoolKeyImage struct {
+	KeyImage Hash   `json:"id_hash"`
+	TxHashes []Hash `json:"txs_hashes"`
+}
+
+// TxPool - ответ get_transaction_pool
+type TxPool struct {
+	Transactions   []PoolTx       `json:"transactions"`
+	SpentKeyImages []PoolKeyImage `json:"spent_key_images"`
+}
+
+// KeyImageStatus - состояние образа ключа из is_key_image_spent
+type KeyImageStatus int
+
+const (
+	KeyImageUnspent KeyImageStatus = iota
+	KeyImageSpentInChain
+	KeyImageSpentInPool
+)
+
+func (s KeyImageStatus) String() string {
+	switch s {
+	case KeyImageUnspent:
+		return "unspent"
+	case KeyImageSpentInChain:
+		return "spent"
+	case KeyImageSpentInPool:
+		return "spent in pool"
+	default:
+		return "unknown"
+	}
+}
+
+// IsSpent сообщает, что образ ключа потрачен в цепочке или в пуле
+func (s KeyImageStatus) IsSpent() bool {
+	return s == KeyImageSpentInChain || s == KeyImageSpentInPool
+}
+
+// DaemonVersion - ответ get_version
+type DaemonVersion struct {
+	Version uint32 `json:"version"`
+	Release bool   `json:"release"`
+}
+
+// Major возвращает старшую часть версии RPC
+func (v DaemonVersion) Major() uint16 {
+	return uint16(v.Version >> 16)
+}
+
+// Minor возвращает младшую часть версии RPC
+func (v DaemonVersion) Minor() uint16 {
+	return uint16(v.Version)
+}
+
+// HardForkInfo - ответ hard_fork_info
+type HardForkInfo struct {
+	Version        uint8  `json:"version"`
+	Enabled        bool   `json:"enabled"`
+	EarliestHeight uint64 `json:"earliest_height"`
+	State          uint32 `json:"state"`
+	Threshold      uint32 `json:"threshold"`
+	Votes          uint32 `json:"votes"`
+	Voting         uint8  `json:"voting"`
+	Window         uint32 `json:"window"`
+}
+
+// HistogramEntry - строка ответа get_output_histogram
+type HistogramEntry struct {
+	Amount            uint64 `json:"amount"`
+	TotalInstances    uint64 `json:"total_instances"`
+	UnlockedInstances uint64 `json:"unlocked_instances"`
+	RecentInstances   uint64 `json:"recent_instances"`
+}
+
+// HistogramRequest - параметры get_output_histogram
+type HistogramRequest struct {
+	Amounts      []uint64
+	MinCount     uint64
+	MaxCount     uint64
+	Unlocked     bool
+	RecentCutoff uint64
+}
+
+// RequestedInfo - что запрашивать у /get_blocks.bin
+type RequestedInfo uint8
+
+const (
+	BlocksOnly RequestedInfo = iota
+	BlocksAndPool
+	PoolOnly
+)
+
+// PoolInfoExtent - полнота сведений о пуле в ответе /get_blocks.bin
+type PoolInfoExtent uint8
+
+const (
+	PoolInfoNone PoolInfoExtent = iota
+	PoolInfoIncremental
+	PoolInfoFull
+)
+
+// BlocksRequest - параметры /get_blocks.bin
+type BlocksRequest struct {
+	// BlockIds - разреженная история известных блоков: от новых к старым, последним - genesis
+	// (см. ChainHistoryHeights). Демон начинает ответ с первого общего блока.
+	BlockIds    []Hash
+	StartHeight uint64
+	Prune       bool
+	NoMinerTx   bool
+
+	RequestedInfo RequestedInfo
+	// PoolInfoSince - время daemon_time прошлого ответа для инкрементальных изменений пула
+	PoolInfoSince uint64
+}
+
+// BlockEntry - блок из /get_blocks.bin
+type BlockEntry struct {
+	Block  *Block
+	Height uint64
+	// OutputIndices - глобальные индексы выходов по транзакциям блока:
+	// [0] - miner tx (если не задан NoMinerTx), далее транзакции в порядке блока
+	OutputIndices [][]uint64
+	// PrunableHashes - хеши prunable частей транзакций, только для pruned блоков
+	PrunableHashes []Hash
+}
+
+// PoolTxInfo - транзакция пула из /get_blocks.bin
+type PoolTxInfo struct {
+	Hash            Hash
+	Blob            []byte
+	DoubleSpendSeen bool
+}
+
+// BlocksResult - ответ /get_blocks.bin
+type BlocksResult struct {
+	Blocks []BlockEntry
+	// StartHeight - высота первого блока ответа; если она меньше высоты,
+	// до которой синхронизирован кошелёк, блоки выше неё были откачены (reorg)
+	StartHeight   uint64
+	CurrentHeight uint64
+	TopBlockHash  Hash
+
+	PoolInfoExtent          PoolInfoExtent
+	AddedPoolTxs            []PoolTxInfo
+	RemainingAddedPoolTxids []Hash
+	RemovedPoolTxids        []Hash
+	DaemonTime              uint64
+}
+
+// ChainHistoryHeights возвращает высоты разреженной истории для BlocksRequest.BlockIds,
+// как в wallet2: 10 последних блоков подряд, затем с шагом 2, 4, 8 ... и genesis
+func ChainHistoryHeights(top uint64) []uint64 {
+	heights := []uint64{}
+	step := uint64(1)
+	for i, h := 0, top; ; i++ {
+		heights = append(heights, h)
+		if h == 0 {
+			return heights
+		}
+		if i >= 10 {
+			step *= 2
+		}
+		if h < step {
+			h = 0
+		} else {
+			h -= step
+		}
+	}
+}
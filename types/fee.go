@@ -0,0 +1,149 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+// FeePriority - уровень комиссии из ответа get_fee_estimate
+type FeePriority int
+
+const (
+	FeePrioritySlow FeePriority = iota
+	FeePriorityNormal
+	FeePriorityFast
+	FeePriorityFastest
+)
+
+func (p FeePriority) String() string {
+	switch p {
+	case FeePrioritySlow:
+		return "slow"
+	case FeePriorityNormal:
+		return "normal"
+	case FeePriorityFast:
+		return "fast"
+	case FeePriorityFastest:
+		return "fastest"
+	default:
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+}
+
+// FeeEstimate - ответ get_fee_estimate: базовая комиссия за байт веса,
+// комиссии по уровням приоритета и маска квантования
+type FeeEstimate struct {
+	Fee              uint64   `json:"fee"`
+	Fees             []uint64 `json:"fees"`
+	QuantizationMask uint64   `json:"quantization_mask"`
+}
+
+// PerByte возвращает комиссию за байт веса для уровня приоритета
+func (f *FeeEstimate) PerByte(priority FeePriority) (util.Amount, error) {
+	if len(f.Fees) == 0 {
+		if f.Fee == 0 {
+			return 0, fmt.Errorf("empty fee estimate")
+		}
+		return util.Amount(f.Fee), nil
+	}
+	if priority < 0 || int(priority) >= len(f.Fees) {
+		return 0, fmt.Errorf("fee priority %s is not available", priority)
+	}
+	return util.Amount(f.Fees[priority]), nil
+}
+
+// FeeForWeight вычисляет комиссию за вес с округлением вверх до маски квантования
+func (f *FeeEstimate) FeeForWeight(weight uint64, priority FeePriority) (util.Amount, error) {
+	perByte, err := f.PerByte(priority)
+	if err != nil {
+		return 0, err
+	}
+	return FeeForWeight(weight, perByte, f.QuantizationMask), nil
+}
+
+// FeeForWeight вычисляет weight * baseFee, округлённое вверх до кратного quantizationMask
+func FeeForWeight(weight uint64, baseFee util.Amount, quantizationMask uint64) util.Amount {
+	fee := weight * uint64(baseFee)
+	if quantizationMask > 1 {
+		fee = (fee + quantizationMask - 1) / quantizationMask * quantizationMask
+	}
+	return util.Amount(fee)
+}
+
+// bulletproofPlusClawback - добавка к весу для транзакций с >2 выходами,
+// компенсирующая логарифмический размер агрегированного доказательства
+func bulletproofPlusClawback(outputs int) uint64 {
+	nlr := 0
+	for (1 << nlr) < outputs {
+		nlr++
+	}
+	paddedOutputs := uint64(1) << nlr
+	if paddedOutputs <= 2 {
+		return 0
+	}
+
+	// Условный размер доказательства на 2 выхода, нормированный на один выход
+	const bpBase = (32 * (6 + 7*2)) / 2
+	bpSize := uint64(32 * (6 + 2*(nlr+6)))
+	return (bpBase*paddedOutputs - bpSize) * 4 / 5
+}
+
+// EstimateTxSize оценивает размер RingCT (type 6) транзакции в байтах
+func EstimateTxSize(inputs, outputs, extraSize int) uint64 {
+	const mixin = RingSize - 1
+
+	size := 0
+	// version, unlock time
+	size += 1 + 6
+	// vin
+	size += inputs * (1 + 6 + (mixin+1)*2 + 32)
+	// vout
+	size += outputs * (6 + 32)
+	// extra
+	size += extraSize
+	// rct type
+	size += 1
+
+	// bulletproof+
+	logPaddedOutputs := 0
+	for (1 << logPaddedOutputs) < outputs {
+		logPaddedOutputs++
+	}
+	size += (2*(6+logPaddedOutputs)+6)*32 + 3
+
+	// CLSAGs
+	size += inputs * (32*(mixin+1) + 64)
+	// view tags
+	size += outputs
+	// pseudoOuts
+	size += 32 * inputs
+	// ecdhInfo
+	size += 8 * outputs
+	// outPk
+	size += 32 * outputs
+	// txnFee
+	size += 4
+
+	return uint64(size)
+}
+
+// EstimateTxWeight оценивает вес транзакции (размер + clawback)
+func EstimateTxWeight(inputs, outputs, extraSize int) uint64 {
+	return EstimateTxSize(inputs, outputs, extraSize) + bulletproofPlusClawback(outputs)
+}
+
+// estimatedExtraSize - размер extra, который пишет CalcExtra:
+// tx pubkey, зашифрованный payment id и additionalKeys дополнительных ключей
+func estimatedExtraSize(additionalKeys int) int {
+	size := (1 + 32) + (1 + 1 + 1 + 8)
+	if additionalKeys > 0 {
+		size += 1 + len(util.EncodeVarint(uint64(additionalKeys))) + 32*additionalKeys
+	}
+	return size
+}
+
+// Weight возвращает вес сериализованной транзакции
+func (tx *Transaction) Weight() uint64 {
+	return uint64(len(tx.Serialize())) + bulletproofPlusClawback(len(tx.Outputs))
+}
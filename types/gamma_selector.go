@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"sort"
+	"strings"
 )
 
 const (
@@ -64,25 +66,19 @@ func getOutputIndex(rpcClient RPCClient, txId string, vout int) (uint64, error)
 		return 0, fmt.Errorf("vout must be non-negative")
 	}
 
-	resp985, err := rpcClient.GetTransactions([]string{txId})
+	txs, err := rpcClient.GetTransactions([]string{txId})
 	if err != nil {
 		return 0, fmt.Errorf("invalid output index value: %w", err)
 	}
 
-	if resp985 == nil || len(*resp985) == 0 {
+	idx := slices.IndexFunc(txs, func(tx TxEntry) bool {
+		return strings.EqualFold(hex.EncodeToString(tx.Hash[:]), txId)
+	})
+	if idx < 0 {
 		return 0, fmt.Errorf("transaction not found: %s", txId)
 	}
 
-	outIndicesIface, ok := (*resp985)[0]["output_indices"]
-	if !ok {
-		return 0, fmt.Errorf("missing output_indices in transaction response")
-	}
-
-	outIndices, ok := outIndicesIface.([]uint64)
-	if !ok {
-		return 0, fmt.Errorf("unexpected output_indices type")
-	}
-
+	outIndices := txs[idx].OutputIndices
 	if vout >= len(outIndices) {
 		return 0, fmt.Errorf("vout out of range: %d >= %d", vout, len(outIndices))
 	}
@@ -153,28 +149,61 @@ func GetMixins(rpcClient RPCClient, keyOffsets []uint64, inputIndx uint64) (*[]M
 		}
 	}
 
-	dests, err := rpcClient.GetOuts(indxs)
+	mixins, err := getOuts(rpcClient, indxs)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if OrderIndx == -1 {
+		return mixins, &OrderIndx, fmt.Errorf("real output index not found among mixin indices")
+	}
+
+	return mixins, &OrderIndx, nil
+}
+
+// GetRingMembers resolves the ring members referenced by relative key offsets.
+func GetRingMembers(rpcClient RPCClient, keyOffsets []uint64) (*[]Mixin, error) {
+	indxs := append([]uint64(nil), keyOffsets...)
+	for i := 1; i < len(indxs); i++ {
+		indxs[i] = indxs[i] + indxs[i-1]
+	}
+
+	return getOuts(rpcClient, indxs)
+}
+
+// FetchRings resolves the rings of every input of the transaction,
+// the result can be passed to VerifyCLSAGs.
+func (t *Transaction) FetchRings(rpcClient RPCClient) ([][]Mixin, error) {
+	rings := make([][]Mixin, 0, len(t.Inputs))
+	for i, input := range t.Inputs {
+		mixins, err := GetRingMembers(rpcClient, input.KeyOffsets)
+		if err != nil {
+			return nil, fmt.Errorf("input %d: %w", i, err)
+		}
+		rings = append(rings, *mixins)
+	}
+	return rings, nil
+}
+
+func getOuts(rpcClient RPCClient, indxs []uint64) (*[]Mixin, error) {
+	dests, err := rpcClient.GetOuts(indxs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dests) != len(indxs) {
+		return nil, fmt.Errorf("outs count mismatch: got %d, expected %d", len(dests), len(indxs))
+	}
+
 	mixins := new([]Mixin)
 	for _, out := range dests {
-		tout := *out
-		dest, _ := hex.DecodeString(tout["key"].(string))
-		mask, _ := hex.DecodeString(tout["mask"].(string))
-
 		*mixins = append(*mixins, Mixin{
-			Dest: Hash(dest),
-			Mask: Hash(mask),
+			Dest: out.Key,
+			Mask: out.Mask,
 		})
 	}
 
-	if OrderIndx == -1 {
-		return mixins, &OrderIndx, fmt.Errorf("real output index not found among mixin indices")
-	}
-
-	return mixins, &OrderIndx, nil
+	return mixins, nil
 }
 
 func SelectDecoys(rng *rand.Rand, realGlobalIndex uint64, maxGlobalIndex uint64) ([]uint64, error) {
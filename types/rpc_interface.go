@@ -1,8 +1,56 @@
 package types
 
+import "context"
+
 // RPCClient определяет интерфейс для взаимодействия с Monero daemon
 type RPCClient interface {
-	GetTransactions(txIds []string) (*[]map[string]interface{}, error)
+	GetTransactions(txIds []string) ([]TxEntry, error)
 	GetOutputDistribution(currentBlockHeight uint64) ([]uint64, error)
-	GetOuts(indxs []uint64) ([]*map[string]interface{}, error)
+	GetOuts(indxs []uint64) ([]OutKey, error)
+	GetHeight() (string, uint64, error)
+	GetFeeEstimate() (*FeeEstimate, error)
+}
+
+// RPCClientContext - RPCClient, вызовы которого можно отменить через context
+type RPCClientContext interface {
+	RPCClient
+	GetTransactionsCtx(ctx context.Context, txIds []string) ([]TxEntry, error)
+	GetOutputDistributionCtx(ctx context.Context, currentBlockHeight uint64) ([]uint64, error)
+	GetOutsCtx(ctx context.Context, indxs []uint64) ([]OutKey, error)
+	GetHeightCtx(ctx context.Context) (string, uint64, error)
+	GetFeeEstimateCtx(ctx context.Context) (*FeeEstimate, error)
+}
+
+// WithContext привязывает ctx к вызовам клиента, если он поддерживает RPCClientContext;
+// иначе клиент возвращается как есть
+func WithContext(ctx context.Context, rpcCli RPCClient) RPCClient {
+	if cli, ok := rpcCli.(RPCClientContext); ok {
+		return &ctxRPCClient{ctx: ctx, cli: cli}
+	}
+	return rpcCli
+}
+
+type ctxRPCClient struct {
+	ctx context.Context
+	cli RPCClientContext
+}
+
+func (c *ctxRPCClient) GetTransactions(txIds []string) ([]TxEntry, error) {
+	return c.cli.GetTransactionsCtx(c.ctx, txIds)
+}
+
+func (c *ctxRPCClient) GetOutputDistribution(currentBlockHeight uint64) ([]uint64, error) {
+	return c.cli.GetOutputDistributionCtx(c.ctx, currentBlockHeight)
+}
+
+func (c *ctxRPCClient) GetOuts(indxs []uint64) ([]OutKey, error) {
+	return c.cli.GetOutsCtx(c.ctx, indxs)
+}
+
+func (c *ctxRPCClient) GetHeight() (string, uint64, error) {
+	return c.cli.GetHeightCtx(c.ctx)
+}
+
+func (c *ctxRPCClient) GetFeeEstimate() (*FeeEstimate, error) {
+	return c.cli.GetFeeEstimateCtx(c.ctx)
 }
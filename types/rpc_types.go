@@ -0,0 +1,103 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TxEntry - транзакция из ответа /get_transactions
+type TxEntry struct {
+	Hash Hash `json:"tx_hash"`
+	// Blob - сериализованная транзакция (as_hex)
+	Blob []byte `json:"-"`
+	// Extra - поле extra, разобранное из Blob
+	Extra             []byte   `json:"-"`
+	BlockHeight       uint64   `json:"block_height"`
+	BlockTimestamp    uint64   `json:"block_timestamp"`
+	Confirmations     uint64   `json:"confirmations"`
+	OutputIndices     []uint64 `json:"output_indices"`
+	InPool            bool     `json:"in_pool"`
+	DoubleSpendSeen   bool     `json:"double_spend_seen"`
+	Relayed           bool     `json:"relayed"`
+	ReceivedTimestamp uint64   `json:"received_timestamp"`
+}
+
+// OutKey - выход из ответа /get_outs
+type OutKey struct {
+	Key      Hash   `json:"key"`
+	Mask     Hash   `json:"mask"`
+	Unlocked bool   `json:"unlocked"`
+	Height   uint64 `json:"height"`
+	Txid     Hash   `json:"txid"`
+}
+
+var ErrTxRejected = errors.New("transaction rejected by daemon")
+
+// SendResult - ответ /send_raw_transaction со всеми причинами отказа
+type SendResult struct {
+	Status            string `json:"status"`
+	Reason            string `json:"reason"`
+	NotRelayed        bool   `json:"not_relayed"`
+	Untrusted         bool   `json:"untrusted"`
+	DoubleSpend       bool   `json:"double_spend"`
+	FeeTooLow         bool   `json:"fee_too_low"`
+	InvalidInput      bool   `json:"invalid_input"`
+	InvalidOutput     bool   `json:"invalid_output"`
+	LowMixin          bool   `json:"low_mixin"`
+	NonzeroUnlockTime bool   `json:"nonzero_unlock_time"`
+	Overspend         bool   `json:"overspend"`
+	SanityCheckFailed bool   `json:"sanity_check_failed"`
+	TooBig            bool   `json:"too_big"`
+	TooFewOutputs     bool   `json:"too_few_outputs"`
+	TxExtraTooBig     bool   `json:"tx_extra_too_big"`
+}
+
+// Accepted сообщает, что демон принял транзакцию
+func (r *SendResult) Accepted() bool {
+	return strings.EqualFold(r.Status, "ok")
+}
+
+// Reasons возвращает выставленные флаги отказа и текстовую причину
+func (r *SendResult) Reasons() []string {
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{r.DoubleSpend, "double_spend"},
+		{r.FeeTooLow, "fee_too_low"},
+		{r.InvalidInput, "invalid_input"},
+		{r.InvalidOutput, "invalid_output"},
+		{r.LowMixin, "low_mixin"},
+		{r.NonzeroUnlockTime, "nonzero_unlock_time"},
+		{r.Overspend, "overspend"},
+		{r.SanityCheckFailed, "sanity_check_failed"},
+		{r.TooBig, "too_big"},
+		{r.TooFewOutputs, "too_few_outputs"},
+		{r.TxExtraTooBig, "tx_extra_too_big"},
+		{r.NotRelayed, "not_relayed"},
+	}
+
+	reasons := []string{}
+	for _, f := range flags {
+		if f.set {
+			reasons = append(reasons, f.name)
+		}
+	}
+	if r.Reason != "" {
+		reasons = append(reasons, r.Reason)
+	}
+	return reasons
+}
+
+// Err возвращает ErrTxRejected с причинами, если транзакция не принята
+func (r *SendResult) Err() error {
+	if r.Accepted() {
+		return nil
+	}
+	reasons := r.Reasons()
+	if len(reasons) == 0 {
+		return fmt.Errorf("%w: status %q", ErrTxRejected, r.Status)
+	}
+	return fmt.Errorf("%w: %s", ErrTxRejected, strings.Join(reasons, ", "))
+}
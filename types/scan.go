@@ -0,0 +1,175 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0xAF4/go-monero/util"
+)
+
+// ErrAmountMismatch - расшифрованная сумма и маска не открывают коммитмент outPk
+var ErrAmountMismatch = errors.New("amount mismatch")
+
+// LogFunc - необязательный хук для отладочных сообщений сканера
+type LogFunc func(format string, args ...interface{})
+
+func (f LogFunc) printf(format string, args ...interface{}) {
+	if f != nil {
+		f(format, args...)
+	}
+}
+
+// OwnedOutput - выход транзакции, принадлежащий кошельку
+type OwnedOutput struct {
+	Index      uint64               `json:"index"`
+	TxHash     Hash                 `json:"tx_hash"`
+	TxPubKey   Hash                 `json:"tx_pub_key"`
+	OneTimeKey Hash                 `json:"one_time_key"`
+	Amount     util.Amount          `json:"amount"`
+	Mask       Hash                 `json:"mask"`
+	Subaddress util.SubaddressIndex `json:"subaddress"`
+	PaymentID  uint64               `json:"payment_id"`
+
+	// Высота блока с транзакцией (0 - неизвестна или в пуле) и unlock_time транзакции
+	BlockHeight uint64 `json:"block_height"`
+	UnlockTime  uint64 `json:"unlock_time"`
+}
+
+const (
+	// SpendableAge - через сколько блоков выход можно тратить
+	SpendableAge = 10
+	// MaxBlockNumber - unlock_time меньше этого значения означает высоту, иначе - timestamp
+	MaxBlockNumber = 500000000
+	// LockedTxAllowedDeltaBlocks/Seconds - допуск при проверке unlock_time
+	LockedTxAllowedDeltaBlocks  = 1
+	LockedTxAllowedDeltaSeconds = 120
+)
+
+// IsUnlocked проверяет, можно ли тратить выход при текущей высоте цепочки
+// (количестве блоков) chainHeight и времени now
+func (o OwnedOutput) IsUnlocked(chainHeight uint64, now time.Time) bool {
+	if o.BlockHeight == 0 || o.BlockHeight+SpendableAge > chainHeight {
+		return false
+	}
+
+	if o.UnlockTime < MaxBlockNumber {
+		return chainHeight-1+LockedTxAllowedDeltaBlocks >= o.UnlockTime
+	}
+	return uint64(now.Unix())+LockedTxAllowedDeltaSeconds >= o.UnlockTime
+}
+
+// ScanOutputs ищет выходы, принадлежащие любому субадресу из таблицы.
+// Ничего не пишет в stdout; отладочные сообщения передаются в logf, если он задан.
+// Выходы, у которых amount*H + mask*G не совпадает с outPk, не зачисляются
+// и возвращаются отдельным списком mismatched.
+func (tx *Transaction) ScanOutputs(table *util.SubaddressTable, logf LogFunc) (owned []OwnedOutput, mismatched []OwnedOutput, err error) {
+	privViewKey := table.PrivateViewKey()
+	privViewKeyBytes := privViewKey.ToBytes2()
+
+	txPubKey, additionalKeys, _, encPID, err := util.ParseTxExtra(tx.Extra)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to extract tx public key: %w", err)
+	}
+
+	var paymentID uint64
+	if encPID != nil && len(txPubKey) == 32 {
+		paymentID, _, err = util.DecryptShortPaymentID(txPubKey, privViewKeyBytes, encPID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decrypt payment id: %w", err)
+		}
+	}
+
+	isRct := tx.RctSignature != nil && tx.RctSignature.Type > 0
+
+	owned = make([]OwnedOutput, 0)
+	for outputIndex, output := range tx.Outputs {
+		var outPubKey []byte
+		var index util.SubaddressIndex
+		for _, candidate := range outputPubKeys(txPubKey, additionalKeys, outputIndex) {
+			derivation, ok := util.GenerateKeyDerivation((*util.Key)(candidate), &privViewKey)
+			if !ok {
+				logf.printf("output %d: invalid tx public key %x", outputIndex, candidate)
+				continue
+			}
+
+			if output.Type == TxOutToTaggedKey {
+				viewTag, _ := util.DeriveViewTag(&derivation, uint64(outputIndex))
+				if byte(output.ViewTag) != viewTag {
+					logf.printf("output %d: view tag mismatch (expected %02x, got %02x)", outputIndex, viewTag, byte(output.ViewTag))
+					continue
+				}
+			}
+
+			spendKey, ok := util.DeriveSubaddressPublicKey((*util.Key)(&output.Target), &derivation, uint64(outputIndex))
+			if !ok {
+				continue
+			}
+
+			if found, ok := table.Lookup(spendKey); ok {
+				outPubKey, index = candidate, found
+				break
+			}
+			logf.printf("output %d: derived spend key %x is not in subaddress table", outputIndex, spendKey)
+		}
+
+		if outPubKey == nil {
+			continue
+		}
+
+		record := OwnedOutput{
+			Index:      uint64(outputIndex),
+			TxHash:     tx.Hash,
+			TxPubKey:   Hash(outPubKey),
+			OneTimeKey: output.Target,
+			Subaddress: index,
+			PaymentID:  paymentID,
+			UnlockTime: tx.UnlockTime,
+		}
+
+		if isRct {
+			if outputIndex >= len(tx.RctSignature.EcdhInfo) {
+				return nil, nil, fmt.Errorf("missing ecdhInfo for output %d", outputIndex)
+			}
+
+			record.Amount, err = DecodeRctAmount(
+				outPubKey,
+				privViewKeyBytes,
+				uint64(outputIndex),
+				tx.RctSignature.EcdhInfo[outputIndex].Amount[:],
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode RCT amount for output %d: %w", outputIndex, err)
+			}
+
+			record.Mask, err = generateBulletproofPlusMask(outPubKey, privViewKeyBytes, uint64(outputIndex))
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to derive commitment mask for output %d: %w", outputIndex, err)
+			}
+
+			if outputIndex >= len(tx.RctSignature.OutPk) {
+				return nil, nil, fmt.Errorf("missing outPk for output %d", outputIndex)
+			}
+
+			commitment, err := CalcCommitment(record.Amount, record.Mask)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to calculate commitment for output %d: %w", outputIndex, err)
+			}
+
+			if commitment != tx.RctSignature.OutPk[outputIndex] {
+				logf.printf("output %d: %s, decoded amount %s does not match outPk commitment", outputIndex, ErrAmountMismatch, record.Amount)
+				mismatched = append(mismatched, record)
+				continue
+			}
+		} else {
+			// Открытая сумма (coinbase): коммитмент = 1*G + amount*H
+			record.Amount = util.Amount(output.Amount)
+			record.Mask = Hash(util.ONE)
+		}
+
+		logf.printf("output %d: owned by subaddress %d/%d, amount %s", outputIndex, index.Major, index.Minor, record.Amount)
+		owned = append(owned, record)
+	}
+
+	return owned, mismatched, nil
+}
@@ -3,6 +3,7 @@ package types
 import (
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 )
 
 type Hash [32]byte
@@ -15,6 +16,27 @@ func (h Hash) MarshalJSON() ([]byte, error) {
 	return json.Marshal(hexStr) // оборачиваем в кавычки
 }
 
+func (h *Hash) UnmarshalJSON(data []byte) error {
+	var hexStr string
+	if err := json.Unmarshal(data, &hexStr); err != nil {
+		return err
+	}
+	// Демон отдаёт пустую строку вместо отсутствующего хеша (например, pow_hash)
+	if hexStr == "" {
+		*h = Hash{}
+		return nil
+	}
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(h) {
+		return fmt.Errorf("invalid hash length %d", len(b))
+	}
+	copy(h[:], b)
+	return nil
+}
+
 func (b HByte) MarshalJSON() ([]byte, error) {
 	hexStr := hex.EncodeToString([]byte{byte(b)})
 	return json.Marshal(hexStr)
@@ -27,13 +27,16 @@ type Transaction struct {
 	RctSignature   *RctSignature   `json:"rct_signature"`
 	RctSigPrunable *RctSigPrunable `json:"rctsig_prunable"`
 
-	POutputs     []TxPrm                `json:"-"`
-	PInputs      []TxPrm                `json:"-"`
-	SecretKey    Hash                   `json:"-"`
-	PublicKey    Hash                   `json:"-"`
-	BlindScalars []*edwards25519.Scalar `json:"-"`
-	InputScalars []*edwards25519.Scalar `json:"-"`
-	BlindAmounts []uint64               `json:"-"`
+	POutputs  []TxPrm `json:"-"`
+	PInputs   []TxPrm `json:"-"`
+	SecretKey Hash    `json:"-"`
+	PublicKey Hash    `json:"-"`
+	// Дополнительные ключи r_i и их публичные части для выходов на субадреса
+	AdditionalSecretKeys []Hash                 `json:"-"`
+	AdditionalPublicKeys []Hash                 `json:"-"`
+	BlindScalars         []*edwards25519.Scalar `json:"-"`
+	InputScalars         []*edwards25519.Scalar `json:"-"`
+	BlindAmounts         []util.Amount          `json:"-"`
 }
 
 type TxInput struct {
@@ -91,7 +94,9 @@ func (tx *Transaction) Serialize() []byte {
 	return concat
 }
 
-func (tx *Transaction) ParseTx() {
+// ParseTx разбирает префикс Raw; при неизвестном типе входа или выхода дальнейшие
+// байты не разобрать, поэтому возвращается ошибка
+func (tx *Transaction) ParseTx() error {
 	reader := bytes.NewReader(tx.Raw)
 
 	// 1. Версия транзакции
@@ -117,7 +122,7 @@ func (tx *Transaction) ParseTx() {
 			}
 			reader.Read(in.KeyImage[:])
 		default:
-			fmt.Printf("⚠️ Unknown TxInput type: 0x%X\n", in.Type)
+			return fmt.Errorf("input %d: unknown type %#x", i, in.Type)
 		}
 		tx.Inputs = append(tx.Inputs, in)
 	}
@@ -128,14 +133,25 @@ func (tx *Transaction) ParseTx() {
 		var out TxOutput
 		out.Amount, _ = levin.ReadVarint(reader)
 		out.Type, _ = reader.ReadByte()
-		reader.Read(out.Target[:])
-		b, _ := reader.ReadByte()
-		out.ViewTag = HByte(b)
+		// view tag есть только у txout_to_tagged_key (с v15)
+		switch out.Type {
+		case TxOutToKey:
+			reader.Read(out.Target[:])
+		case TxOutToTaggedKey:
+			reader.Read(out.Target[:])
+			b, _ := reader.ReadByte()
+			out.ViewTag = HByte(b)
+		default:
+			return fmt.Errorf("output %d: unknown type %#x", i, out.Type)
+		}
 		tx.Outputs = append(tx.Outputs, out)
 	}
 
 	// 5. Extra
 	extraLen, _ := levin.ReadVarint(reader)
+	if extraLen > uint64(reader.Len()) {
+		return fmt.Errorf("extra length %d exceeds remaining %d bytes", extraLen, reader.Len())
+	}
 	extra := make([]byte, extraLen)
 	reader.Read(extra)
 	tx.Extra = extra
@@ -143,6 +159,7 @@ func (tx *Transaction) ParseTx() {
 	rest := make([]byte, reader.Len())
 	reader.Read(rest)
 	tx.RctRaw = rest
+	return nil
 }
 
 func (tx *Transaction) ParseRctSig() {
@@ -228,7 +245,7 @@ func (tx *Transaction) ParseRctSig() {
 	tx.RctRaw = rest
 }
 
-func (tx *Transaction) CheckOutputs(address string, privateViewKey string) (float64, uint64, error) {
+func (tx *Transaction) CheckOutputs(address string, privateViewKey string) (util.Amount, uint64, error) {
 	pubSpendKey, pubViewKey, err := util.DecodeAddress(address) // correct ✅
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to decode address: %w", err)
@@ -243,82 +260,59 @@ func (tx *Transaction) CheckOutputs(address string, privateViewKey string) (floa
 		return 0, 0, fmt.Errorf("private view key does not match address")
 	}
 
-	// txPubKey, err := extractTxPubKey(tx.Extra) // correct ✅
-	txPubKey, _, _, encPID, err := util.ParseTxExtra(tx.Extra)
+	table := util.NewSubaddressTable(util.Key(privViewKeyBytes), util.Key(pubSpendKey))
+	owned, mismatched, err := tx.ScanOutputs(table, nil)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to extract tx public key: %w", err)
+		return 0, 0, err
 	}
 
-	// Check each output
-	var totalAmount float64
-	var foundOutputs int
-
-	for outputIndex, output := range tx.Outputs {
-		// Derive the one-time public key and view tag
-		viewTagByte, err := DeriveViewTag(txPubKey, privViewKeyBytes, uint64(outputIndex))
-		if err != nil {
-			continue
-		}
-
-		// If view tag exists and doesn't match, log it but don't immediately skip — fall back to full derived-key check
-		if byte(output.ViewTag) != viewTagByte {
-			fmt.Printf("Output %d: View tag mismatch (expected %02x, got %02x), will still check derived key\n", outputIndex, viewTagByte, byte(output.ViewTag))
-			continue
-		} else {
-			fmt.Println("Output view_tag is match✅")
+	if len(owned) == 0 {
+		if len(mismatched) > 0 {
+			return 0, 0, fmt.Errorf("output %d: %w", mismatched[0].Index, ErrAmountMismatch)
 		}
+		return 0, 0, fmt.Errorf("no outputs found for this address")
+	}
 
-		derivedKey, err := util.DerivePublicKeyMy(txPubKey, privViewKeyBytes, pubSpendKey[:], uint64(outputIndex))
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to derive public key for output %d: %w", outputIndex, err)
-		}
+	var totalAmount util.Amount
+	for _, output := range owned {
+		totalAmount += output.Amount
+	}
+	return totalAmount, owned[0].PaymentID, nil
+}
 
-		// Compare derived key with output target (this is authoritative)
-		if !util.EqualBytes(derivedKey, output.Target[:]) {
-			fmt.Printf("Output %d: Derived key %x does not match output target %x\n", outputIndex, derivedKey, output.Target)
-			continue // Not our output
-		} else {
-			fmt.Printf("Output %d: Derived key matches output target ✅\n", outputIndex)
-		}
+// CheckSubaddressOutputs ищет выходы, принадлежащие любому субадресу из таблицы,
+// и возвращает суммы, сгруппированные по индексу субадреса
+func (tx *Transaction) CheckSubaddressOutputs(table *util.SubaddressTable) (map[util.SubaddressIndex]util.Amount, uint64, error) {
+	owned, mismatched, err := tx.ScanOutputs(table, nil)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		// This output belongs to us!
-		foundOutputs++
-		var amount float64
-
-		// If it's an RCT transaction, decode the amount
-		if tx.RctSignature != nil && tx.RctSignature.Type > 0 {
-			if outputIndex < len(tx.RctSignature.EcdhInfo) {
-				amount, err = DecodeRctAmount(
-					txPubKey,
-					privViewKeyBytes,
-					uint64(outputIndex),
-					tx.RctSignature.EcdhInfo[outputIndex].Amount[:],
-				)
-				if err != nil {
-					return 0, 0, fmt.Errorf("failed to decode RCT amount for output %d: %w", outputIndex, err)
-				} else {
-					fmt.Printf("Output %d: Decoded RCT amount: %.12f\n", outputIndex, amount)
-				}
-			}
-		} else {
-			amount = 0
+	if len(owned) == 0 {
+		if len(mismatched) > 0 {
+			return nil, 0, fmt.Errorf("output %d: %w", mismatched[0].Index, ErrAmountMismatch)
 		}
-
-		totalAmount += amount
+		return nil, 0, fmt.Errorf("no outputs found for this wallet")
 	}
 
-	if foundOutputs == 0 {
-		return 0, 0, fmt.Errorf("no outputs found for this address")
+	result := make(map[util.SubaddressIndex]util.Amount)
+	for _, output := range owned {
+		result[output.Subaddress] += output.Amount
 	}
+	return result, owned[0].PaymentID, nil
+}
 
-	if encPID != nil {
-		id, _, err := util.DecryptShortPaymentID(txPubKey, privViewKeyBytes, encPID)
-		if err != nil {
-			return 0, 0, err
-		}
-		return totalAmount, id, nil
+// outputPubKeys возвращает ключи, с которыми нужно проверять выход:
+// основной ключ транзакции и дополнительный ключ выхода (tag 0x04), если он есть
+func outputPubKeys(txPubKey []byte, additionalKeys [][]byte, outputIndex int) [][]byte {
+	keys := make([][]byte, 0, 2)
+	if len(txPubKey) == 32 {
+		keys = append(keys, txPubKey)
+	}
+	if outputIndex < len(additionalKeys) && len(additionalKeys[outputIndex]) == 32 {
+		keys = append(keys, additionalKeys[outputIndex])
 	}
-	return totalAmount, 0, nil
+	return keys
 }
 
 func (tx *Transaction) CalculatePart1() []byte {
@@ -352,7 +346,9 @@ func (tx *Transaction) CalculatePart1() []byte {
 		buf.Write(util.EncodeVarint(output.Amount))
 		buf.WriteByte(output.Type)
 		buf.Write(output.Target[:])
-		buf.WriteByte(byte(output.ViewTag))
+		if output.Type == TxOutToTaggedKey {
+			buf.WriteByte(byte(output.ViewTag))
+		}
 	}
 
 	// Extra
@@ -394,7 +390,7 @@ func (tx *Transaction) CalculatePart2() []byte {
 func (tx *Transaction) CalculatePart3() []byte {
 	var buf bytes.Buffer
 
-	buf.Write(util.EncodeVarint(1))
+	buf.Write(util.EncodeVarint(uint64(len(tx.RctSigPrunable.Bpp))))
 	for _, bpp := range tx.RctSigPrunable.Bpp {
 		buf.Write(bpp.A[:])
 		buf.Write(bpp.A1[:])
@@ -479,7 +475,7 @@ func verifyViewKeyPair(privViewKey []byte, pubViewKey []byte) bool {
 }
 
 // decodeRctAmount decodes an encrypted RCT amount
-func DecodeRctAmount(txPubKey []byte, privateViewKey []byte, outputIndex uint64, encryptedAmount []byte) (float64, error) {
+func DecodeRctAmount(txPubKey []byte, privateViewKey []byte, outputIndex uint64, encryptedAmount []byte) (util.Amount, error) {
 	if len(encryptedAmount) != 8 {
 		return 0, fmt.Errorf("invalid encrypted amount length: %d", len(encryptedAmount))
 	}
@@ -535,13 +531,13 @@ func DecodeRctAmount(txPubKey []byte, privateViewKey []byte, outputIndex uint64,
 	amountMask := util.Keccak256(append([]byte("amount"), hsBytes...))
 
 	// XOR first 8 bytes (little-endian) to get amount
-	var amount uint64
+	var amount util.Amount
 	for i := 0; i < 8; i++ {
 		decrypted := encryptedAmount[i] ^ amountMask[i]
-		amount |= uint64(decrypted) << (8 * i)
+		amount |= util.Amount(decrypted) << (8 * i)
 	}
 
-	return util.AtomicToXmr(amount, 1e12), nil
+	return amount, nil
 }
 
 // decodeRctAmount decodes an encrypted RCT amount
@@ -590,9 +586,7 @@ func generateBulletproofPlusMask(txPubKey []byte, privateViewKey []byte, outputI
 // - x is the blinding factor (mask) derived from shared secret
 // - a is the amount in atomic units
 // - G is the base point, H is the second base point
-func CalcOutPk(derivation *util.Key, outputIndex uint64, amount float64, pubSpendKey []byte) (*edwards25519.Scalar, Hash, error) {
-	// Convert amount to atomic units
-	amountAtomic := util.XmrToAtomic(amount, 1e12)
+func CalcOutPk(derivation *util.Key, outputIndex uint64, amount util.Amount, pubSpendKey []byte) (*edwards25519.Scalar, Hash, error) {
 	sharedSecret := derivation.ToBytes2()
 
 	// Compute Hs(shared_secret || index) - derivation scalar
@@ -620,7 +614,7 @@ func CalcOutPk(derivation *util.Key, outputIndex uint64, amount float64, pubSpen
 		return nil, Hash{}, fmt.Errorf("failed to derive blinding factor: %w", err)
 	}
 
-	commitment, err := CalcCommitment(amountAtomic, [32]byte(blindingFactor.Bytes()))
+	commitment, err := CalcCommitment(amount, [32]byte(blindingFactor.Bytes()))
 	if err != nil {
 		return nil, Hash{}, fmt.Errorf("CalcCommitment error: %w", err)
 	}
@@ -628,7 +622,7 @@ func CalcOutPk(derivation *util.Key, outputIndex uint64, amount float64, pubSpen
 	return blindingFactor, commitment, nil
 }
 
-func CalcCommitment(amount uint64, mask [32]byte) (Hash, error) {
+func CalcCommitment(amount util.Amount, mask [32]byte) (Hash, error) {
 	blindingFactor := new(edwards25519.Scalar)
 	if _, err := blindingFactor.SetCanonicalBytes(mask[:]); err != nil {
 		return Hash{}, fmt.Errorf("failed to derive blinding factor: %w", err)
@@ -636,7 +630,7 @@ func CalcCommitment(amount uint64, mask [32]byte) (Hash, error) {
 
 	// Create scalar from amount (little-endian)
 	amountBytes := make([]byte, 32)
-	binary.LittleEndian.PutUint64(amountBytes, amount)
+	binary.LittleEndian.PutUint64(amountBytes, uint64(amount))
 	amountScalar := new(edwards25519.Scalar)
 	if _, err := amountScalar.SetCanonicalBytes(amountBytes); err != nil {
 		return Hash{}, fmt.Errorf("failed to create amount scalar: %w", err)
@@ -717,7 +711,9 @@ func (o *TxOutput) Serialize() []byte {
 	buf.Write(util.EncodeVarint(0))
 	buf.WriteByte(o.Type)
 	buf.Write(o.Target[:])
-	buf.WriteByte(byte(o.ViewTag))
+	if o.Type == TxOutToTaggedKey {
+		buf.WriteByte(byte(o.ViewTag))
+	}
 
 	return buf.Bytes()
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/rand"
+	"sort"
 	"time"
 
 	"filippo.io/edwards25519"
@@ -69,37 +70,111 @@ func (t *Transaction) WriteOutput(prm TxPrm) {
 	t.POutputs = append(t.POutputs, prm)
 }
 
-func (t *Transaction) SetFee(fee float64) {
-	t.RctSignature.TxnFee = util.XmrToAtomic(fee, 1e12)
+func (t *Transaction) SetFee(fee util.Amount) {
+	t.RctSignature.TxnFee = uint64(fee)
 }
 
-func (t *Transaction) CalcFeeDifference() {
-	inputSM := uint64(0)
-	for _, val := range t.PInputs {
-		inputSM += util.XmrToAtomic(val["amount"].(float64), 1e12)
+// CalcFeeDifference устанавливает комиссию как разницу сумм входов и выходов
+func (t *Transaction) CalcFeeDifference() error {
+	inputSM, err := sumPrmAmounts(t.PInputs)
+	if err != nil {
+		return fmt.Errorf("invalid input amount: %w", err)
 	}
 
-	outputSM := uint64(0)
-	for _, val := range t.POutputs {
-		outputSM += util.XmrToAtomic(val["amount"].(float64), 1e12)
+	outputSM, err := sumPrmAmounts(t.POutputs)
+	if err != nil {
+		return fmt.Errorf("invalid output amount: %w", err)
 	}
 
-	t.RctSignature.TxnFee = (inputSM - outputSM)
+	fee, err := inputSM.Sub(outputSM)
+	if err != nil {
+		return fmt.Errorf("outputs exceed inputs: %w", err)
+	}
+
+	t.SetFee(fee)
+	return nil
+}
+
+// prmAmount читает prm["amount"]: util.Amount или uint64 - атомарные единицы,
+// string - десятичная сумма в XMR. float64 не принимается из-за потери точности.
+func prmAmount(prm TxPrm) (util.Amount, error) {
+	switch v := prm["amount"].(type) {
+	case util.Amount:
+		return v, nil
+	case uint64:
+		return util.Amount(v), nil
+	case string:
+		return util.ParseAmount(v)
+	case float64:
+		return 0, fmt.Errorf("float64 amount %v is not supported, use util.Amount", v)
+	case nil:
+		return 0, fmt.Errorf("amount is missing")
+	default:
+		return 0, fmt.Errorf("unsupported amount type %T", v)
+	}
+}
+
+func sumPrmAmounts(prms []TxPrm) (util.Amount, error) {
+	var sum util.Amount
+	for _, prm := range prms {
+		amount, err := prmAmount(prm)
+		if err != nil {
+			return 0, err
+		}
+		if sum, err = sum.Add(amount); err != nil {
+			return 0, err
+		}
+	}
+	return sum, nil
+}
+
+// NeedAdditionalTxKeys сообщает, нужны ли дополнительные tx pubkey (тег 0x04)
+// для получателей addresses (без сдачи): как и в wallet2, они нужны,
+// если среди получателей есть субадрес и кроме него есть другие адреса
+func NeedAdditionalTxKeys(addresses []string) bool {
+	subaddresses, standard := countDestinations(addresses)
+	return subaddresses > 0 && (standard > 0 || subaddresses > 1)
+}
+
+// countDestinations считает уникальные субадреса и обычные адреса
+func countDestinations(addresses []string) (subaddresses, standard int) {
+	seen := make(map[string]bool)
+	for _, addr := range addresses {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		if util.IsSubAddress(addr) {
+			subaddresses++
+		} else {
+			standard++
+		}
+	}
+	return subaddresses, standard
 }
 
 func (t *Transaction) CalcExtra() error {
-	//Считаем количество выходов и вычисляем txPublicKey вместе с extra
-	outs := 0
+	if len(t.POutputs) > MaxOutputs {
+		return fmt.Errorf("too many outputs: %d, at most %d allowed", len(t.POutputs), MaxOutputs)
+	}
+
+	//Считаем получателей и вычисляем txPublicKey вместе с extra
 	pubSpendKey := [32]byte{}
 	pubViewKey := [32]byte{}
 	paymentId := []byte{}
+	destinations := []string{}
 	err := error(nil)
 	for _, val := range t.POutputs {
-		if val2, ok := val["change_address"].(bool); !ok || !val2 {
-			addr := val["address"].(string)
-			if util.IsSubAddress(addr) {
-				outs += 1
+		isChange, err := prmBool(val, "change_address")
+		if err != nil {
+			return err
+		}
+		if !isChange {
+			addr, err := prmString(val, "address")
+			if err != nil {
+				return err
 			}
+			destinations = append(destinations, addr)
 
 			pubSpendKey, pubViewKey, err = util.DecodeAddress(addr) // correct ✅
 			if err != nil {
@@ -122,10 +197,11 @@ func (t *Transaction) CalcExtra() error {
 		s.SetUniformBytes(append(t.SecretKey[:], t.SecretKey[:]...))
 	}
 
-	if outs == 1 {
+	subaddresses, standard := countDestinations(destinations)
+	if subaddresses == 1 && standard == 0 {
 		D := new(edwards25519.Point)
 		if _, err := D.SetBytes(pubSpendKey[:]); err != nil {
-			panic(err)
+			return fmt.Errorf("invalid subaddress spend key: %w", err)
 		}
 
 		sD := new(edwards25519.Point).ScalarMult(s, D) // s * D
@@ -135,6 +211,31 @@ func (t *Transaction) CalcExtra() error {
 		t.PublicKey = Hash(sG.Bytes())
 	}
 
+	// Для каждого выхода свой ключ r_i: r_i*D_i для субадреса, иначе r_i*G
+	t.AdditionalSecretKeys = nil
+	t.AdditionalPublicKeys = nil
+	if NeedAdditionalTxKeys(destinations) {
+		for _, val := range t.POutputs {
+			addr, err := prmString(val, "address")
+			if err != nil {
+				return err
+			}
+			spend, _, err := util.DecodeAddress(addr)
+			if err != nil {
+				return fmt.Errorf("failed to decode address: %w", err)
+			}
+
+			r := util.RandomScalar()
+			R := *r.PubKey()
+			if util.IsSubAddress(addr) {
+				D := util.Key(spend)
+				R = util.ScalarMult(r, &D)
+			}
+			t.AdditionalSecretKeys = append(t.AdditionalSecretKeys, Hash(*r))
+			t.AdditionalPublicKeys = append(t.AdditionalPublicKeys, Hash(R))
+		}
+	}
+
 	var buf bytes.Buffer
 
 	buf.WriteByte(0x01)
@@ -148,6 +249,14 @@ func (t *Transaction) CalcExtra() error {
 	}
 	buf.Write(encryptedPaymentId[:])
 
+	if len(t.AdditionalPublicKeys) > 0 {
+		buf.WriteByte(0x04) // Additional pubkeys tag
+		buf.Write(util.EncodeVarint(uint64(len(t.AdditionalPublicKeys))))
+		for _, key := range t.AdditionalPublicKeys {
+			buf.Write(key[:])
+		}
+	}
+
 	t.Extra = ByteArray(buf.Bytes())
 
 	return nil
@@ -159,10 +268,35 @@ func (t *Transaction) CalcInputs(rpcCli RPCClient, currentBlockHeight uint64) er
 			return err
 		}
 	}
+	t.sortInputs()
 	return nil
 }
 
+// sortInputs сортирует входы по key image по убыванию, как требует консенсус
+func (t *Transaction) sortInputs() {
+	order := make([]int, len(t.Inputs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return bytes.Compare(t.Inputs[order[i]].KeyImage[:], t.Inputs[order[j]].KeyImage[:]) > 0
+	})
+
+	inputs := make([]TxInput, len(t.Inputs))
+	pInputs := make([]TxPrm, len(t.PInputs))
+	for i, j := range order {
+		inputs[i] = t.Inputs[j]
+		pInputs[i] = t.PInputs[j]
+	}
+	t.Inputs = inputs
+	t.PInputs = pInputs
+}
+
 func (t *Transaction) CalcOutputs() error {
+	if len(t.POutputs) < MinOutputs || len(t.POutputs) > MaxOutputs {
+		return fmt.Errorf("transaction has %d outputs, expected %d to %d", len(t.POutputs), MinOutputs, MaxOutputs)
+	}
+
 	for _, val := range t.POutputs {
 		if err := t.writeOutput2(val); err != nil {
 			return err
@@ -171,96 +305,189 @@ func (t *Transaction) CalcOutputs() error {
 	return nil
 }
 
-func toInt(val interface{}) int {
-	switch n := val.(type) {
+// prmUint64 читает неотрицательное целое значение параметра
+func prmUint64(prm TxPrm, key string) (uint64, error) {
+	switch n := prm[key].(type) {
 	case uint:
-		return int(n)
+		return uint64(n), nil
 	case uint8:
-		return int(n)
+		return uint64(n), nil
 	case uint16:
-		return int(n)
+		return uint64(n), nil
 	case uint32:
-		return int(n)
+		return uint64(n), nil
 	case uint64:
-		return int(n)
+		return n, nil
 	case int:
-		return int(n)
+		if n >= 0 {
+			return uint64(n), nil
+		}
 	case int8:
-		return int(n)
+		if n >= 0 {
+			return uint64(n), nil
+		}
 	case int16:
-		return int(n)
+		if n >= 0 {
+			return uint64(n), nil
+		}
 	case int32:
-		return int(n)
+		if n >= 0 {
+			return uint64(n), nil
+		}
 	case int64:
-		return int(n)
+		if n >= 0 {
+			return uint64(n), nil
+		}
 	case float64:
-		return int(n)
+		if n >= 0 && n == float64(uint64(n)) {
+			return uint64(n), nil
+		}
+	case nil:
+		return 0, fmt.Errorf("parameter %q is missing", key)
 	default:
-		panic("cant cast to int")
+		return 0, fmt.Errorf("parameter %q has unsupported type %T", key, n)
+	}
+	return 0, fmt.Errorf("parameter %q must be a non-negative integer", key)
+}
+
+func prmString(prm TxPrm, key string) (string, error) {
+	switch v := prm[key].(type) {
+	case string:
+		if v == "" {
+			return "", fmt.Errorf("parameter %q is empty", key)
+		}
+		return v, nil
+	case nil:
+		return "", fmt.Errorf("parameter %q is missing", key)
+	default:
+		return "", fmt.Errorf("parameter %q has unsupported type %T", key, v)
+	}
+}
+
+// prmBool читает флаг; отсутствующий параметр означает false
+func prmBool(prm TxPrm, key string) (bool, error) {
+	switch v := prm[key].(type) {
+	case bool:
+		return v, nil
+	case nil:
+		return false, nil
+	default:
+		return false, fmt.Errorf("parameter %q has unsupported type %T", key, v)
+	}
+}
+
+// prmKey читает 32-байтный ключ: util.Key, Hash, [32]byte или hex строку
+func prmKey(prm TxPrm, key string) (util.Key, error) {
+	switch v := prm[key].(type) {
+	case util.Key:
+		return v, nil
+	case Hash:
+		return util.Key(v), nil
+	case [32]byte:
+		return util.Key(v), nil
+	case string:
+		k, err := util.ParseKeyFromHex(v)
+		if err != nil {
+			return util.Key{}, fmt.Errorf("parameter %q: %w", key, err)
+		}
+		return k, nil
+	case nil:
+		return util.Key{}, fmt.Errorf("parameter %q is missing", key)
+	default:
+		return util.Key{}, fmt.Errorf("parameter %q has unsupported type %T", key, v)
 	}
 }
 
 func (t *Transaction) writeInput2(rpcCli RPCClient, currentBlockHeight uint64, prm TxPrm) error {
-	vout := toInt(prm["vout"])
-	indx, err := getOutputIndex(rpcCli, prm["txId"].(string), vout)
+	vout, err := prmUint64(prm, "vout")
 	if err != nil {
-		return fmt.Errorf("failed to get output index: %w", err)
+		return err
 	}
 
-	maxIndx, err := getMaxGlobalIndex(rpcCli, currentBlockHeight)
+	txId, err := prmString(prm, "txId")
 	if err != nil {
-		return fmt.Errorf("failed to get max global index: %w", err)
+		return err
 	}
 
-	ring, err := SelectDecoys(rand.New(rand.NewSource(time.Now().UnixNano())), indx, maxIndx)
+	address, err := prmString(prm, "address")
 	if err != nil {
-		return fmt.Errorf("failed to select decoys: %w", err)
+		return err
 	}
 
-	keyOffset, err := BuildKeyOffsets(ring)
+	mPrivViewKey, err := prmKey(prm, "privateViewKey")
 	if err != nil {
-		return fmt.Errorf("failed to build key offsets: %w", err)
+		return err
 	}
 
-	if len(ring) != 16 {
-		return fmt.Errorf("invalid ring size: got %d, expected %d", len(ring), 16)
+	mSecSpendKey, err := prmKey(prm, "privateSpendKey")
+	if err != nil {
+		return err
 	}
 
-	mixins, OrderIndx, err := GetMixins(rpcCli, keyOffset, indx)
+	pubSpendKey, _, err := util.DecodeAddress(address) // correct ✅
 	if err != nil {
-		return fmt.Errorf("Get Mixins Error: %w", err)
+		return fmt.Errorf("failed to decode address: %w", err)
 	}
 
-	privViewKeyBytes, err := util.HexTo32(prm["privateViewKey"].(string)) // correct ✅
+	// Ключ транзакции, с которым был найден выход; если не задан - берём из extra
+	mTxPubKey, err := prmKey(prm, "txPubKey")
 	if err != nil {
-		return fmt.Errorf("failed to decode private view key: %w", err)
+		extraHex, err := prmString(prm, "extra")
+		if err != nil {
+			return err
+		}
+
+		extra, err := hex.DecodeString(extraHex) // correct ✅
+		if err != nil {
+			return fmt.Errorf("failed to decode extra: %w", err)
+		}
+
+		txPubKey, _, _, _, err := util.ParseTxExtra(extra)
+		if err != nil {
+			return fmt.Errorf("failed to extract tx public key: %w", err)
+		}
+		if len(txPubKey) != 32 {
+			return fmt.Errorf("tx public key not found in extra")
+		}
+		mTxPubKey = util.Key(txPubKey)
 	}
 
-	pubSpendKey, _, err := util.DecodeAddress(prm["address"].(string)) // correct ✅
+	indx, err := getOutputIndex(rpcCli, txId, int(vout))
 	if err != nil {
-		return fmt.Errorf("failed to decode address: %w", err)
+		return fmt.Errorf("failed to get output index: %w", err)
 	}
 
-	extra, err := hex.DecodeString(prm["extra"].(string)) // correct ✅
+	maxIndx, err := getMaxGlobalIndex(rpcCli, currentBlockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to decode extra: %w", err)
+		return fmt.Errorf("failed to get max global index: %w", err)
+	}
+
+	ring, err := SelectDecoys(rand.New(rand.NewSource(time.Now().UnixNano())), indx, maxIndx)
+	if err != nil {
+		return fmt.Errorf("failed to select decoys: %w", err)
+	}
+
+	keyOffset, err := BuildKeyOffsets(ring)
+	if err != nil {
+		return fmt.Errorf("failed to build key offsets: %w", err)
+	}
+
+	if len(ring) != 16 {
+		return fmt.Errorf("invalid ring size: got %d, expected %d", len(ring), 16)
 	}
 
-	txPubKey, _, _, _, err := util.ParseTxExtra(extra)
+	mixins, OrderIndx, err := GetMixins(rpcCli, keyOffset, indx)
 	if err != nil {
-		return fmt.Errorf("failed to extract tx public key: %w", err)
+		return fmt.Errorf("Get Mixins Error: %w", err)
 	}
 
-	mPrivViewKey := util.Key(privViewKeyBytes)
 	mPubSpendKey := util.Key(pubSpendKey)
-	mTxPubKey := util.Key(txPubKey)
-	mSecSpendKey, err := util.ParseKeyFromHex(prm["privateSpendKey"].(string))
-	keyImage, derivedPriKey, err := util.CreateKeyImage(&mPubSpendKey, &mSecSpendKey, &mPrivViewKey, &mTxPubKey, uint64(vout))
+	keyImage, derivedPriKey, err := util.CreateKeyImage(&mPubSpendKey, &mSecSpendKey, &mPrivViewKey, &mTxPubKey, vout)
 	if err != nil {
 		return fmt.Errorf("failed to create key image using moneroutil: %w", err)
 	}
 
-	inputMask, err := generateBulletproofPlusMask(txPubKey, privViewKeyBytes, uint64(vout))
+	inputMask, err := generateBulletproofPlusMask(mTxPubKey[:], mPrivViewKey[:], vout)
 	if err != nil {
 		return fmt.Errorf("failed to generate mask: %w", err)
 	}
@@ -270,7 +497,7 @@ func (t *Transaction) writeInput2(rpcCli RPCClient, currentBlockHeight uint64, p
 		Type:       0x02,
 		KeyOffsets: keyOffset,
 		KeyImage:   keyImage.ToBytes(),
-		Address:    prm["address"].(string),
+		Address:    address,
 		Mixins:     *mixins,
 		OrderIndx:  *OrderIndx,
 		InSk: Mixin{
@@ -285,7 +512,17 @@ func (t *Transaction) writeOutput2(prm TxPrm) error {
 	// Implementation for writing output goes here
 	currentIndex := t.VoutCount
 
-	pubSpendKey, pubViewKey, err := util.DecodeAddress(prm["address"].(string)) // correct ✅
+	address, err := prmString(prm, "address")
+	if err != nil {
+		return err
+	}
+
+	isChange, err := prmBool(prm, "change_address")
+	if err != nil {
+		return err
+	}
+
+	pubSpendKey, pubViewKey, err := util.DecodeAddress(address) // correct ✅
 	if err != nil {
 		return fmt.Errorf("failed to decode address: %w", err)
 	}
@@ -300,12 +537,18 @@ func (t *Transaction) writeOutput2(prm TxPrm) error {
 		ok         bool
 	)
 
-	if !prm["change_address"].(bool) {
+	if !isChange {
+		if len(t.AdditionalSecretKeys) > 0 {
+			if currentIndex >= uint64(len(t.AdditionalSecretKeys)) {
+				return fmt.Errorf("missing additional tx key for output %d", currentIndex)
+			}
+			mTxSecretKey = util.Key(t.AdditionalSecretKeys[currentIndex])
+		}
 		derivation, ok = util.GenerateKeyDerivation(&mPubViewKey, &mTxSecretKey)
 	} else {
-		mViewSecretKey, err := util.ParseKeyFromHex(prm["privateViewKey"].(string))
+		mViewSecretKey, err := prmKey(prm, "privateViewKey")
 		if err != nil {
-			return fmt.Errorf("Error of decodind Private View Key for change address")
+			return fmt.Errorf("Error of decodind Private View Key for change address: %w", err)
 		}
 		derivation, ok = util.GenerateKeyDerivation(&mTxPublicKey, &mViewSecretKey)
 	}
@@ -323,12 +566,17 @@ func (t *Transaction) writeOutput2(prm TxPrm) error {
 		return fmt.Errorf("failed to derive view tag: %w", err)
 	}
 
-	amnt, err := util.EncryptRctAmount(&derivation, currentIndex, prm["amount"].(float64))
+	amount, err := prmAmount(prm)
+	if err != nil {
+		return err
+	}
+
+	amnt, err := util.EncryptRctAmount(&derivation, currentIndex, amount)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt amount: %w", err)
 	}
 
-	blind, outPk, err := CalcOutPk(&derivation, currentIndex, prm["amount"].(float64), pubSpendKey[:])
+	blind, outPk, err := CalcOutPk(&derivation, currentIndex, amount, pubSpendKey[:])
 	if err != nil {
 		return fmt.Errorf("failed to calculate output public key: %w", err)
 	}
@@ -349,17 +597,19 @@ func (t *Transaction) writeOutput2(prm TxPrm) error {
 	})
 
 	t.BlindScalars = append(t.BlindScalars, blind)
-	t.BlindAmounts = append(t.BlindAmounts, util.XmrToAtomic(prm["amount"].(float64), 1e12))
+	t.BlindAmounts = append(t.BlindAmounts, amount)
 
 	return nil
 }
 
 func (t *Transaction) SignTransaction() error {
-	Bpp, err := t.signBpp()
+	bpp, err := t.signBpp()
 	if err != nil {
 		return fmt.Errorf("failed to sign bpp: %w", err)
 	}
-	t.RctSigPrunable.Bpp[0] = Bpp
+	// В RCTTypeBulletproofPlus одно агрегированное доказательство на все выходы
+	t.RctSigPrunable.Bpp = []Bpp{bpp}
+	t.RctSigPrunable.Nbp = uint64(len(t.RctSigPrunable.Bpp))
 
 	PseudoOuts, err := t.calculatePseudoOuts()
 	if err != nil {
@@ -0,0 +1,377 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"filippo.io/edwards25519"
+	"github.com/0xAF4/go-monero/util"
+)
+
+const (
+	RingSize        = 16
+	MaxOutputs      = 16
+	MinOutputs      = 2
+	MaxTxExtraSize  = 1060
+	TxExtraPadding  = 0x00
+	TxExtraMergeTag = 0x03
+	TxExtraMinerTag = 0xde
+)
+
+// ViolationCode определяет вид нарушенного правила
+type ViolationCode string
+
+const (
+	ViolationVersion           ViolationCode = "version"
+	ViolationRctType           ViolationCode = "rct_type"
+	ViolationInputType         ViolationCode = "input_type"
+	ViolationInputCount        ViolationCode = "input_count"
+	ViolationInputOrder        ViolationCode = "input_order"
+	ViolationRingSize          ViolationCode = "ring_size"
+	ViolationKeyOffsets        ViolationCode = "key_offsets"
+	ViolationKeyImage          ViolationCode = "key_image"
+	ViolationDuplicateKeyImage ViolationCode = "duplicate_key_image"
+	ViolationOutputCount       ViolationCode = "output_count"
+	ViolationOutputType        ViolationCode = "output_type"
+	ViolationOutputKey         ViolationCode = "output_key"
+	ViolationOutputAmount      ViolationCode = "output_amount"
+	ViolationExtra             ViolationCode = "extra"
+	ViolationSignatureCount    ViolationCode = "signature_count"
+	ViolationCommitmentBalance ViolationCode = "commitment_balance"
+	ViolationRangeProof        ViolationCode = "range_proof"
+	ViolationRingSignature     ViolationCode = "ring_signature"
+)
+
+// Violation описывает одно нарушение.
+// Index указывает на вход/выход, к которому относится нарушение, либо -1.
+type Violation struct {
+	Code    ViolationCode `json:"code"`
+	Index   int           `json:"index"`
+	Message string        `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Index >= 0 {
+		return fmt.Sprintf("%s[%d]: %s", v.Code, v.Index, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Code, v.Message)
+}
+
+// Violations - список нарушений, найденных ValidateTransaction
+type Violations []Violation
+
+func (v Violations) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, violation := range v {
+		msgs = append(msgs, violation.String())
+	}
+	return "invalid transaction: " + strings.Join(msgs, "; ")
+}
+
+// Err возвращает nil, если нарушений нет
+func (v Violations) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	return v
+}
+
+// Has проверяет, есть ли нарушение с указанным кодом
+func (v Violations) Has(code ViolationCode) bool {
+	for _, violation := range v {
+		if violation.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Violations) add(code ViolationCode, index int, format string, args ...interface{}) {
+	*v = append(*v, Violation{
+		Code:    code,
+		Index:   index,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidateTransaction выполняет семантические проверки RingCT (type 6) транзакции
+// так же, как это делает демон перед добавлением в пул.
+// rings - разрешённые участники колец (см. FetchRings); если nil,
+// проверка кольцевых подписей пропускается.
+func ValidateTransaction(tx *Transaction, rings [][]Mixin) Violations {
+	violations := Violations{}
+
+	if tx == nil {
+		violations.add(ViolationVersion, -1, "transaction is nil")
+		return violations
+	}
+
+	if tx.Version != 2 {
+		violations.add(ViolationVersion, -1, "unsupported version %d", tx.Version)
+	}
+
+	if tx.RctSignature == nil || tx.RctSigPrunable == nil {
+		violations.add(ViolationRctType, -1, "missing RingCT signature")
+		return violations
+	}
+
+	if tx.RctSignature.Type != uint64(util.RCTTypeBulletproofPlus) {
+		violations.add(ViolationRctType, -1, "unsupported RingCT type %d", tx.RctSignature.Type)
+		return violations
+	}
+
+	validateInputs(tx, &violations)
+	validateOutputs(tx, &violations)
+	validateExtra(tx, &violations)
+
+	countsOk := true
+	if len(tx.RctSigPrunable.CLSAGs) != len(tx.Inputs) {
+		violations.add(ViolationSignatureCount, -1, "CLSAGs count %d != inputs count %d", len(tx.RctSigPrunable.CLSAGs), len(tx.Inputs))
+		countsOk = false
+	}
+	if len(tx.RctSigPrunable.PseudoOuts) != len(tx.Inputs) {
+		violations.add(ViolationSignatureCount, -1, "pseudo outs count %d != inputs count %d", len(tx.RctSigPrunable.PseudoOuts), len(tx.Inputs))
+		countsOk = false
+	}
+	if len(tx.RctSignature.EcdhInfo) != len(tx.Outputs) {
+		violations.add(ViolationSignatureCount, -1, "ecdhInfo count %d != outputs count %d", len(tx.RctSignature.EcdhInfo), len(tx.Outputs))
+		countsOk = false
+	}
+	if len(tx.RctSignature.OutPk) != len(tx.Outputs) {
+		violations.add(ViolationSignatureCount, -1, "outPk count %d != outputs count %d", len(tx.RctSignature.OutPk), len(tx.Outputs))
+		countsOk = false
+	}
+	if len(tx.RctSigPrunable.Bpp) != 1 {
+		violations.add(ViolationSignatureCount, -1, "bulletproof+ count %d != 1", len(tx.RctSigPrunable.Bpp))
+		countsOk = false
+	}
+
+	if !countsOk {
+		return violations
+	}
+
+	if err := checkCommitmentBalance(tx); err != nil {
+		violations.add(ViolationCommitmentBalance, -1, "%s", err)
+	}
+
+	if err := tx.VerifyBulletproofPlus(); err != nil {
+		violations.add(ViolationRangeProof, -1, "%s", err)
+	}
+
+	if rings != nil {
+		if err := tx.VerifyCLSAGs(rings); err != nil {
+			violations.add(ViolationRingSignature, -1, "%s", err)
+		}
+	}
+
+	return violations
+}
+
+func validateInputs(tx *Transaction, violations *Violations) {
+	if len(tx.Inputs) == 0 {
+		violations.add(ViolationInputCount, -1, "transaction has no inputs")
+		return
+	}
+
+	seen := make(map[Hash]int)
+	for i, input := range tx.Inputs {
+		if input.Type != 0x02 {
+			violations.add(ViolationInputType, i, "unsupported input type 0x%02x", input.Type)
+			continue
+		}
+
+		if input.Amount != 0 {
+			violations.add(ViolationInputType, i, "RingCT input has non-zero amount %d", input.Amount)
+		}
+
+		if len(input.KeyOffsets) != RingSize {
+			violations.add(ViolationRingSize, i, "ring size %d, expected %d", len(input.KeyOffsets), RingSize)
+		}
+
+		for j := 1; j < len(input.KeyOffsets); j++ {
+			if input.KeyOffsets[j] == 0 {
+				violations.add(ViolationKeyOffsets, i, "key offsets are not strictly increasing at position %d", j)
+				break
+			}
+		}
+
+		if !isInPrimeSubgroup(input.KeyImage) {
+			violations.add(ViolationKeyImage, i, "key image %x is not in the prime-order subgroup", input.KeyImage)
+		}
+
+		if j, ok := seen[input.KeyImage]; ok {
+			violations.add(ViolationDuplicateKeyImage, i, "key image duplicates input %d", j)
+		} else {
+			seen[input.KeyImage] = i
+		}
+
+		// Входы должны быть отсортированы по key image по убыванию
+		if i > 0 && bytes.Compare(input.KeyImage[:], tx.Inputs[i-1].KeyImage[:]) >= 0 {
+			violations.add(ViolationInputOrder, i, "inputs are not sorted by key image")
+		}
+	}
+}
+
+func validateOutputs(tx *Transaction, violations *Violations) {
+	if len(tx.Outputs) < MinOutputs {
+		violations.add(ViolationOutputCount, -1, "transaction has %d outputs, at least %d required", len(tx.Outputs), MinOutputs)
+	}
+	if len(tx.Outputs) > MaxOutputs {
+		violations.add(ViolationOutputCount, -1, "transaction has %d outputs, at most %d allowed", len(tx.Outputs), MaxOutputs)
+	}
+
+	for i, output := range tx.Outputs {
+		if output.Type != TxOutToTaggedKey {
+			violations.add(ViolationOutputType, i, "output type 0x%02x, expected tagged key 0x%02x", output.Type, TxOutToTaggedKey)
+		}
+		if output.Amount != 0 {
+			violations.add(ViolationOutputAmount, i, "RingCT output has non-zero amount %d", output.Amount)
+		}
+		if _, err := new(edwards25519.Point).SetBytes(output.Target[:]); err != nil {
+			violations.add(ViolationOutputKey, i, "output key is not a valid point")
+		}
+	}
+}
+
+func validateExtra(tx *Transaction, violations *Violations) {
+	extra := []byte(tx.Extra)
+	if len(extra) > MaxTxExtraSize {
+		violations.add(ViolationExtra, -1, "extra size %d exceeds %d bytes", len(extra), MaxTxExtraSize)
+	}
+
+	pubKeys := 0
+	additionalKeys := -1
+
+	i := 0
+loop:
+	for i < len(extra) {
+		tag := extra[i]
+		i++
+
+		switch tag {
+		case TxExtraPadding:
+			// Padding занимает весь остаток extra и состоит только из нулей
+			for ; i < len(extra); i++ {
+				if extra[i] != 0 {
+					violations.add(ViolationExtra, -1, "non-zero byte in padding")
+					break loop
+				}
+			}
+
+		case util.TX_EXTRA_TAG_PUBKEY:
+			if i+32 > len(extra) {
+				violations.add(ViolationExtra, -1, "truncated tx public key")
+				break loop
+			}
+			if _, err := new(edwards25519.Point).SetBytes(extra[i : i+32]); err != nil {
+				violations.add(ViolationExtra, -1, "tx public key is not a valid point")
+			}
+			pubKeys++
+			i += 32
+
+		case util.TX_EXTRA_NONCE:
+			if i >= len(extra) {
+				violations.add(ViolationExtra, -1, "nonce length missing")
+				break loop
+			}
+			L := int(extra[i])
+			i++
+			if i+L > len(extra) {
+				violations.add(ViolationExtra, -1, "truncated nonce")
+				break loop
+			}
+			i += L
+
+		case util.TX_EXTRA_ADDITIONAL_PUBKEYS:
+			if i >= len(extra) {
+				violations.add(ViolationExtra, -1, "additional keys length missing")
+				break loop
+			}
+			count64, n, err := util.DecodeVarint(extra[i:])
+			if err != nil || count64 > uint64(len(extra)-i-n)/32 {
+				violations.add(ViolationExtra, -1, "invalid additional keys")
+				break loop
+			}
+			count := int(count64)
+			i += n
+			if i+count*32 > len(extra) {
+				violations.add(ViolationExtra, -1, "truncated additional keys")
+				break loop
+			}
+			if additionalKeys >= 0 {
+				violations.add(ViolationExtra, -1, "duplicate additional keys field")
+			}
+			additionalKeys = count
+			i += count * 32
+
+		case TxExtraMergeTag, TxExtraMinerTag:
+			L, n, err := util.DecodeVarint(extra[i:])
+			if err != nil || L > uint64(len(extra)-i-n) {
+				violations.add(ViolationExtra, -1, "truncated field 0x%02x", tag)
+				break loop
+			}
+			i += n + int(L)
+
+		default:
+			// Неизвестные теги не являются нарушением, но дальше разбирать нельзя
+			break loop
+		}
+	}
+
+	if pubKeys == 0 {
+		violations.add(ViolationExtra, -1, "missing tx public key")
+	}
+	if pubKeys > 1 {
+		violations.add(ViolationExtra, -1, "duplicate tx public key")
+	}
+	if additionalKeys >= 0 && additionalKeys != len(tx.Outputs) {
+		violations.add(ViolationExtra, -1, "additional keys count %d != outputs count %d", additionalKeys, len(tx.Outputs))
+	}
+}
+
+// checkCommitmentBalance проверяет sum(pseudoOuts) == sum(outPk) + fee*H
+func checkCommitmentBalance(tx *Transaction) error {
+	sumPseudo := edwards25519.NewIdentityPoint()
+	for i, pseudoOut := range tx.RctSigPrunable.PseudoOuts {
+		point, err := new(edwards25519.Point).SetBytes(pseudoOut[:])
+		if err != nil {
+			return fmt.Errorf("pseudo out %d is not a valid point", i)
+		}
+		sumPseudo.Add(sumPseudo, point)
+	}
+
+	sumOut := edwards25519.NewIdentityPoint()
+	for i, outPk := range tx.RctSignature.OutPk {
+		point, err := new(edwards25519.Point).SetBytes(outPk[:])
+		if err != nil {
+			return fmt.Errorf("outPk %d is not a valid point", i)
+		}
+		sumOut.Add(sumOut, point)
+	}
+
+	feeH := new(edwards25519.Point).ScalarMult(AmountToScalar(tx.RctSignature.TxnFee), getH())
+	sumOut.Add(sumOut, feeH)
+
+	if sumPseudo.Equal(sumOut) != 1 {
+		return fmt.Errorf("sum of pseudo outs does not match sum of outputs plus fee")
+	}
+
+	return nil
+}
+
+// isInPrimeSubgroup проверяет, что точка корректна, не является нейтральным
+// элементом и не имеет компоненты малого порядка: P == INV_EIGHT * (8 * P)
+func isInPrimeSubgroup(h Hash) bool {
+	point, err := new(edwards25519.Point).SetBytes(h[:])
+	if err != nil {
+		return false
+	}
+	if point.Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return false
+	}
+
+	p8 := new(edwards25519.Point).MultByCofactor(point)
+	check := new(edwards25519.Point).ScalarMult(util.INV_EIGHT_E, p8)
+	return check.Equal(point) == 1
+}
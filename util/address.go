@@ -0,0 +1,246 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Network - сеть Monero, определяет префиксы адресов
+type Network int
+
+const (
+	Mainnet Network = iota
+	Testnet
+	Stagenet
+)
+
+func (n Network) String() string {
+	switch n {
+	case Mainnet:
+		return "mainnet"
+	case Testnet:
+		return "testnet"
+	case Stagenet:
+		return "stagenet"
+	default:
+		return fmt.Sprintf("network(%d)", int(n))
+	}
+}
+
+// ParseNetwork разбирает имя сети ("mainnet", "testnet", "stagenet")
+func ParseNetwork(s string) (Network, error) {
+	for _, n := range networks {
+		if n.String() == s {
+			return n, nil
+		}
+	}
+	return Mainnet, fmt.Errorf("unknown network %q", s)
+}
+
+// AddressKind - вид адреса
+type AddressKind int
+
+const (
+	AddressStandard AddressKind = iota
+	AddressIntegrated
+	AddressSubaddress
+)
+
+func (k AddressKind) String() string {
+	switch k {
+	case AddressStandard:
+		return "standard"
+	case AddressIntegrated:
+		return "integrated"
+	case AddressSubaddress:
+		return "subaddress"
+	default:
+		return fmt.Sprintf("kind(%d)", int(k))
+	}
+}
+
+// Префиксы адресов (cryptonote_config.h)
+const (
+	MainnetAddressPrefix    = 0x12
+	MainnetIntegratedPrefix = 0x13
+	MainnetSubaddressPrefix = 0x2A
+
+	TestnetAddressPrefix    = 0x35
+	TestnetIntegratedPrefix = 0x36
+	TestnetSubaddressPrefix = 0x3F
+
+	StagenetAddressPrefix    = 0x18
+	StagenetIntegratedPrefix = 0x19
+	StagenetSubaddressPrefix = 0x24
+)
+
+const (
+	PaymentIDLength = 8
+
+	addressLength           = 1 + 2*KeyLength + 4
+	integratedAddressLength = addressLength + PaymentIDLength
+)
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// AddressPrefix возвращает префикс адреса для сети и вида
+func AddressPrefix(network Network, kind AddressKind) (byte, error) {
+	if network < Mainnet || network > Stagenet {
+		return 0, fmt.Errorf("%w: unknown %s %s", ErrInvalidAddress, network, kind)
+	}
+	return network.Params().AddressPrefixFor(kind)
+}
+
+// Address - разобранный адрес Monero
+type Address struct {
+	Network        Network     `json:"network"`
+	Kind           AddressKind `json:"kind"`
+	PublicSpendKey Key         `json:"public_spend_key"`
+	PublicViewKey  Key         `json:"public_view_key"`
+	// PaymentID - только для интегрированного адреса
+	PaymentID [PaymentIDLength]byte `json:"payment_id"`
+}
+
+// NewAddress создаёт стандартный адрес или субадрес
+func NewAddress(network Network, kind AddressKind, pubSpend, pubView Key) (Address, error) {
+	if kind == AddressIntegrated {
+		return Address{}, fmt.Errorf("%w: use NewIntegratedAddress for integrated addresses", ErrInvalidAddress)
+	}
+	if _, err := AddressPrefix(network, kind); err != nil {
+		return Address{}, err
+	}
+	return Address{Network: network, Kind: kind, PublicSpendKey: pubSpend, PublicViewKey: pubView}, nil
+}
+
+// NewIntegratedAddress создаёт интегрированный адрес с 8-байтным payment ID
+func NewIntegratedAddress(network Network, pubSpend, pubView Key, paymentID [PaymentIDLength]byte) (Address, error) {
+	if _, err := AddressPrefix(network, AddressIntegrated); err != nil {
+		return Address{}, err
+	}
+	return Address{
+		Network:        network,
+		Kind:           AddressIntegrated,
+		PublicSpendKey: pubSpend,
+		PublicViewKey:  pubView,
+		PaymentID:      paymentID,
+	}, nil
+}
+
+// WithPaymentID возвращает интегрированный адрес для стандартного адреса
+func (a Address) WithPaymentID(paymentID [PaymentIDLength]byte) (Address, error) {
+	if a.Kind == AddressSubaddress {
+		return Address{}, fmt.Errorf("%w: subaddress cannot carry a payment ID", ErrInvalidAddress)
+	}
+	return NewIntegratedAddress(a.Network, a.PublicSpendKey, a.PublicViewKey, paymentID)
+}
+
+// Standard возвращает адрес без payment ID; субадрес возвращается как есть
+func (a Address) Standard() Address {
+	if a.Kind == AddressIntegrated {
+		a.Kind = AddressStandard
+		a.PaymentID = [PaymentIDLength]byte{}
+	}
+	return a
+}
+
+func (a Address) IsSubaddress() bool {
+	return a.Kind == AddressSubaddress
+}
+
+func (a Address) IsIntegrated() bool {
+	return a.Kind == AddressIntegrated
+}
+
+// Prefix возвращает сетевой префикс адреса
+func (a Address) Prefix() byte {
+	prefix, _ := AddressPrefix(a.Network, a.Kind)
+	return prefix
+}
+
+// String кодирует адрес в base58
+func (a Address) String() string {
+	payload := make([]byte, 0, integratedAddressLength)
+	payload = append(payload, a.Prefix())
+	payload = append(payload, a.PublicSpendKey[:]...)
+	payload = append(payload, a.PublicViewKey[:]...)
+	if a.Kind == AddressIntegrated {
+		payload = append(payload, a.PaymentID[:]...)
+	}
+	payload = append(payload, Keccak256(payload)[:4]...)
+	return encodeMoneroBase58(payload)
+}
+
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Address) UnmarshalText(text []byte) error {
+	parsed, err := ParseAddress(string(text))
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// ParseAddress разбирает адрес любой сети и вида, проверяя контрольную сумму
+// и то, что ключи - точки кривой
+func ParseAddress(s string) (Address, error) {
+	b, err := decodeMoneroBase58(s)
+	if err != nil {
+		return Address{}, fmt.Errorf("%w: %s", ErrInvalidAddress, err)
+	}
+	if len(b) < addressLength {
+		return Address{}, fmt.Errorf("%w: decoded address too short", ErrInvalidAddress)
+	}
+
+	addr := Address{}
+	found := false
+	for _, network := range networks {
+		for _, kind := range []AddressKind{AddressStandard, AddressIntegrated, AddressSubaddress} {
+			if prefix, _ := AddressPrefix(network, kind); prefix == b[0] {
+				addr.Network, addr.Kind, found = network, kind, true
+			}
+		}
+	}
+	if !found {
+		return Address{}, fmt.Errorf("%w: unknown network byte 0x%02x", ErrInvalidAddress, b[0])
+	}
+
+	expectedLength := addressLength
+	if addr.Kind == AddressIntegrated {
+		expectedLength = integratedAddressLength
+	}
+	if len(b) != expectedLength {
+		return Address{}, fmt.Errorf("%w: unknown format (len=%d, network_byte=0x%02x)", ErrInvalidAddress, len(b), b[0])
+	}
+
+	checksumStart := expectedLength - 4
+	if !EqualBytes(Keccak256(b[:checksumStart])[:4], b[checksumStart:]) {
+		return Address{}, fmt.Errorf("%w: address checksum mismatch", ErrInvalidAddress)
+	}
+
+	copy(addr.PublicSpendKey[:], b[1:33])
+	copy(addr.PublicViewKey[:], b[33:65])
+	if addr.Kind == AddressIntegrated {
+		copy(addr.PaymentID[:], b[65:73])
+	}
+
+	if !new(ExtendedGroupElement).FromBytes(&addr.PublicSpendKey) || !new(ExtendedGroupElement).FromBytes(&addr.PublicViewKey) {
+		return Address{}, fmt.Errorf("%w: public keys are not valid curve points", ErrInvalidAddress)
+	}
+
+	return addr, nil
+}
+
+// ParseAddressForNetwork разбирает адрес и проверяет, что он принадлежит сети network
+func ParseAddressForNetwork(s string, network Network) (Address, error) {
+	addr, err := ParseAddress(s)
+	if err != nil {
+		return Address{}, err
+	}
+	if addr.Network != network {
+		return Address{}, fmt.Errorf("%w: %s address, expected %s", ErrInvalidAddress, addr.Network, network)
+	}
+	return addr, nil
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const AmountDecimals = 12
+
+// Amount - сумма в атомарных единицах (piconero), 1 XMR = 10^12
+type Amount uint64
+
+const XMR Amount = 1_000_000_000_000
+
+var maxAmount = decimal.NewFromUint64(math.MaxUint64)
+
+// ParseAmount разбирает десятичную строку в XMR ("1.5", "0.000000000001")
+// без потери точности
+func ParseAmount(s string) (Amount, error) {
+	d, err := decimal.NewFromString(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return AmountFromDecimal(d)
+}
+
+// AmountFromDecimal переводит сумму в XMR в атомарные единицы.
+// Дробная часть длиннее 12 знаков считается ошибкой, а не округляется.
+func AmountFromDecimal(d decimal.Decimal) (Amount, error) {
+	if d.IsNegative() {
+		return 0, errors.New("amount must not be negative")
+	}
+
+	atomic := d.Shift(AmountDecimals)
+	if !atomic.Equal(atomic.Truncate(0)) {
+		return 0, fmt.Errorf("amount %s has more than %d decimal places", d, AmountDecimals)
+	}
+	if atomic.GreaterThan(maxAmount) {
+		return 0, fmt.Errorf("amount %s overflows uint64", d)
+	}
+
+	return Amount(atomic.BigInt().Uint64()), nil
+}
+
+// Decimal возвращает сумму в XMR
+func (a Amount) Decimal() decimal.Decimal {
+	return decimal.NewFromUint64(uint64(a)).Shift(-AmountDecimals)
+}
+
+// String возвращает сумму в XMR без лишних нулей, например "1.5"
+func (a Amount) String() string {
+	return a.Decimal().String()
+}
+
+// StringFixed возвращает сумму в XMR со всеми 12 знаками после точки
+func (a Amount) StringFixed() string {
+	return a.Decimal().StringFixed(AmountDecimals)
+}
+
+func (a Amount) Uint64() uint64 {
+	return uint64(a)
+}
+
+// Add складывает суммы и возвращает ошибку при переполнении
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a > math.MaxUint64-b {
+		return 0, errors.New("amount overflow")
+	}
+	return a + b, nil
+}
+
+// Sub вычитает суммы и возвращает ошибку, если результат отрицательный
+func (a Amount) Sub(b Amount) (Amount, error) {
+	if b > a {
+		return 0, fmt.Errorf("amount underflow: %s - %s", a, b)
+	}
+	return a - b, nil
+}
@@ -80,6 +80,46 @@ func decodeMoneroBase58(s string) ([]byte, error) {
 	return out, nil
 }
 
+// encodedBlockSizes - длина base58 блока в символах для блока из N байт
+var encodedBlockSizes = [...]int{0, 2, 3, 5, 6, 7, 9, 10, 11}
+
+// encodeMoneroBase58 encodes bytes into Monero's special base58 (8-byte blocks).
+func encodeMoneroBase58(data []byte) string {
+	var sb strings.Builder
+	for i := 0; i < len(data); i += 8 {
+		end := i + 8
+		if end > len(data) {
+			end = len(data)
+		}
+		block := data[i:end]
+
+		val := new(big.Int).SetBytes(block)
+		chars := make([]byte, encodedBlockSizes[len(block)])
+		for j := range chars {
+			chars[j] = moneroBase58Alphabet[0]
+		}
+
+		mod := new(big.Int)
+		base := big.NewInt(58)
+		for j := len(chars) - 1; j >= 0 && val.Sign() > 0; j-- {
+			val.DivMod(val, base, mod)
+			chars[j] = moneroBase58Alphabet[mod.Int64()]
+		}
+		sb.Write(chars)
+	}
+	return sb.String()
+}
+
+// EncodeAddress encodes network prefix and public keys into a base58 Monero address
+func EncodeAddress(prefix byte, pubSpend, pubView [32]byte) string {
+	payload := make([]byte, 0, 69)
+	payload = append(payload, prefix)
+	payload = append(payload, pubSpend[:]...)
+	payload = append(payload, pubView[:]...)
+	payload = append(payload, Keccak256(payload)[:4]...)
+	return encodeMoneroBase58(payload)
+}
+
 func DecodeAddressRaw(s string) ([]byte, error) {
 	return decodeMoneroBase58(s)
 }
@@ -98,91 +138,34 @@ func KeccakByte(data []byte) byte {
 	return h[0]
 }
 
-// Проверка, является ли адрес субадресом
+// Проверка, является ли адрес субадресом (любой сети)
 func IsSubAddress(addr string) bool {
-	b, err := decodeMoneroBase58(addr)
-	if err != nil {
-		return false
-	}
-
-	if len(b) < 69 {
-		return false
-	}
-
-	networkByte := b[0]
-	return networkByte == 0x2A
+	parsed, err := ParseAddress(addr)
+	return err == nil && parsed.IsSubaddress()
 }
 
-// DecodeAddress decodes a standard or integrated Monero address and returns public spend and view keys
+// DecodeAddress decodes a standard, integrated or subaddress of any network and returns public spend and view keys
 func DecodeAddress(addr string) (pubSpend [32]byte, pubView [32]byte, err error) {
-	b, err := decodeMoneroBase58(addr)
+	parsed, err := ParseAddress(addr)
 	if err != nil {
 		return pubSpend, pubView, err
 	}
-
-	if len(b) < 69 {
-		return pubSpend, pubView, errors.New("decoded address too short")
-	}
-
-	networkByte := b[0]
-
-	var checksumDataLen int
-	var checksumStart int
-
-	switch {
-	case len(b) == 69 && (networkByte == 0x12 || networkByte == 0x2A):
-		// Standard address or subaddress
-		checksumDataLen = 65
-		checksumStart = 65
-
-	case len(b) == 77 && networkByte == 0x13:
-		// Integrated address (has 8-byte payment_id after pubView)
-		checksumDataLen = 73
-		checksumStart = 73
-
-	default:
-		return pubSpend, pubView, fmt.Errorf("invalid address: unknown format (len=%d, network_byte=0x%02x)", len(b), networkByte)
-	}
-
-	// Extract keys (same position for all types)
-	copy(pubSpend[:], b[1:33])
-	copy(pubView[:], b[33:65])
-
-	// Verify checksum
-	payload := b[:checksumDataLen]
-	sum := Keccak256(payload)
-
-	expectedChecksum := b[checksumStart : checksumStart+4]
-	if !EqualBytes(sum[:4], expectedChecksum) {
-		return pubSpend, pubView, errors.New("address checksum mismatch")
-	}
-
-	return pubSpend, pubView, nil
+	return parsed.PublicSpendKey, parsed.PublicViewKey, nil
 }
 
 // ExtractPaymentID extracts payment_id from an integrated address
 // Returns empty slice for standard addresses
 func ExtractPaymentID(addr string) ([]byte, error) {
-	b, err := decodeMoneroBase58(addr)
+	parsed, err := ParseAddress(addr)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(b) < 69 {
-		return nil, errors.New("decoded address too short")
-	}
-
-	networkByte := b[0]
-
-	// Only integrated addresses (0x13) have payment_id
-	if len(b) == 77 && networkByte == 0x13 {
-		paymentID := make([]byte, 8)
-		copy(paymentID, b[65:73])
-		return paymentID, nil
-	}
-
 	// Standard or subaddress - no payment_id
-	return nil, nil
+	if !parsed.IsIntegrated() {
+		return nil, nil
+	}
+	return append([]byte(nil), parsed.PaymentID[:]...), nil
 }
 
 func EqualBytes(a, b []byte) bool {
@@ -243,6 +226,18 @@ func EncodeVarint(n uint64) []byte {
 	return buf
 }
 
+// DecodeVarint читает varint из начала b и возвращает значение и число прочитанных байт
+func DecodeVarint(b []byte) (uint64, int, error) {
+	var result uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		result |= uint64(b[i]&0x7F) << (7 * uint(i))
+		if b[i]&0x80 == 0 {
+			return result, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("invalid varint")
+}
+
 func DerivePublicKeyMy(txPubKey, privateViewKey, pubSpendKey []byte, index uint64) ([]byte, error) {
 	// Validate input lengths
 	if len(txPubKey) != 32 {
@@ -404,18 +399,21 @@ func ParseTxExtra(extra []byte) (txPubKey []byte, additionalKeys [][]byte, pid [
 			if i >= len(extra) {
 				return nil, nil, nil, nil, errors.New("tx extra: additional keys length missing")
 			}
-			L := int(extra[i])
-			i++
-			if i+L > len(extra) || L%32 != 0 {
+			// vector<public_key>: varint количество ключей, затем ключи
+			count, n, err := DecodeVarint(extra[i:])
+			if err != nil {
+				return nil, nil, nil, nil, errors.New("tx extra: invalid additional keys count")
+			}
+			i += n
+			if count > uint64(len(extra)-i)/32 {
 				return nil, nil, nil, nil, errors.New("tx extra: invalid additional keys")
 			}
-			count := L / 32
-			for j := 0; j < count; j++ {
+			for j := 0; j < int(count); j++ {
 				key := make([]byte, 32)
 				copy(key, extra[i+j*32:i+(j+1)*32])
 				additionalKeys = append(additionalKeys, key)
 			}
-			i += L
+			i += int(count) * 32
 
 		default:
 			// неизвестный тег — в Monero их может быть больше
@@ -514,6 +512,7 @@ func ScalarFromUint64(v uint64) *edwards25519.Scalar {
 	return sc
 }
 
+// Deprecated: float64 теряет точность, используйте ParseAmount/AmountFromDecimal
 func XmrToAtomic(xmr float64, e int64) uint64 {
 	d := decimal.NewFromFloat(xmr)
 	multiplier := decimal.NewFromInt(e)
@@ -521,6 +520,7 @@ func XmrToAtomic(xmr float64, e int64) uint64 {
 	return uint64(result.IntPart())
 }
 
+// Deprecated: float64 теряет точность, используйте Amount.String/Amount.Decimal
 func AtomicToXmr(atomic uint64, e int64) float64 {
 	d := decimal.NewFromUint64(atomic)
 	divisor := decimal.NewFromInt(e)
@@ -104,10 +104,7 @@ func DeriveSecretKey(derivation *Key, outIndex uint64, base *Key) (derivedKey Ke
 	return
 }
 
-func EncryptRctAmount(derivation *Key, outputIndex uint64, amount float64) ([8]byte, error) {
-	// Конвертируем amount в uint64 (предполагаем, что amount уже в atomic units)
-	amountAtomic := XmrToAtomic(amount, 1e12)
-
+func EncryptRctAmount(derivation *Key, outputIndex uint64, amount Amount) ([8]byte, error) {
 	// Получаем shared secret (shared = 8 * txSecretKey * pubViewKey)
 	shared := derivation.ToBytes2()
 
@@ -129,7 +126,7 @@ func EncryptRctAmount(derivation *Key, outputIndex uint64, amount float64) ([8]b
 
 	// Конвертируем amount в байты (little-endian)
 	amountBytes := make([]byte, 8)
-	binary.LittleEndian.PutUint64(amountBytes, amountAtomic)
+	binary.LittleEndian.PutUint64(amountBytes, uint64(amount))
 
 	// XOR первых 8 байт маски
 	var encrypted [8]byte
@@ -0,0 +1,230 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	// MnemonicWords - количество слов в seed с контрольным словом
+	MnemonicWords = 25
+	// mnemonicDataWords - слова, кодирующие 32 байта seed (по 3 слова на 4 байта)
+	mnemonicDataWords = 24
+)
+
+var (
+	ErrMnemonicLength   = errors.New("mnemonic must have 24 or 25 words")
+	ErrMnemonicWord     = errors.New("unknown mnemonic word")
+	ErrMnemonicChecksum = errors.New("invalid mnemonic checksum word")
+	ErrMnemonicLanguage = errors.New("unknown mnemonic language")
+)
+
+// MnemonicLanguage - словарь 25-словной мнемоники Monero (Electrum-style).
+// Слова различаются по первым PrefixLength символам, по ним же считается
+// контрольное слово.
+type MnemonicLanguage struct {
+	Name         string
+	EnglishName  string
+	PrefixLength int
+	Words        []string
+
+	once     sync.Once
+	prefixes map[string]int
+}
+
+// English - английский словарь Monero
+var English = &MnemonicLanguage{
+	Name:         "English",
+	EnglishName:  "English",
+	PrefixLength: 3,
+	Words:        englishWords,
+}
+
+var mnemonicLanguages = []*MnemonicLanguage{English}
+
+// MnemonicLanguages возвращает поддерживаемые словари
+func MnemonicLanguages() []*MnemonicLanguage {
+	return append([]*MnemonicLanguage(nil), mnemonicLanguages...)
+}
+
+// MnemonicLanguageByName ищет словарь по имени (родному или английскому), без учёта регистра
+func MnemonicLanguageByName(name string) (*MnemonicLanguage, error) {
+	for _, lang := range mnemonicLanguages {
+		if strings.EqualFold(lang.Name, name) || strings.EqualFold(lang.EnglishName, name) {
+			return lang, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrMnemonicLanguage, name)
+}
+
+// prefix возвращает первые PrefixLength символов слова (по рунам, не байтам)
+func (l *MnemonicLanguage) prefix(word string) string {
+	if utf8.RuneCountInString(word) <= l.PrefixLength {
+		return word
+	}
+	return string([]rune(word)[:l.PrefixLength])
+}
+
+// wordIndex возвращает индекс слова; слово может быть сокращено до префикса
+func (l *MnemonicLanguage) wordIndex(word string) (int, bool) {
+	l.once.Do(func() {
+		l.prefixes = make(map[string]int, len(l.Words))
+		for i, w := range l.Words {
+			l.prefixes[l.prefix(w)] = i
+		}
+	})
+
+	i, ok := l.prefixes[l.prefix(word)]
+	return i, ok
+}
+
+// checksumIndex вычисляет индекс контрольного слова среди первых 24
+func (l *MnemonicLanguage) checksumIndex(words []string) int {
+	var prefixes strings.Builder
+	for _, w := range words {
+		prefixes.WriteString(l.prefix(w))
+	}
+	return int(crc32.ChecksumIEEE([]byte(prefixes.String())) % uint32(len(words)))
+}
+
+// SeedToMnemonic кодирует 32-байтный seed (секретный ключ траты) в 25 слов:
+// каждые 4 байта - 3 слова, последнее слово - контрольное
+func SeedToMnemonic(seed Key, lang *MnemonicLanguage) (string, error) {
+	if lang == nil {
+		lang = English
+	}
+	if len(lang.Words) == 0 {
+		return "", fmt.Errorf("%w: %s has no words", ErrMnemonicLanguage, lang.EnglishName)
+	}
+
+	n := uint32(len(lang.Words))
+	words := make([]string, 0, MnemonicWords)
+	for i := 0; i < KeyLength; i += 4 {
+		x := binary.LittleEndian.Uint32(seed[i : i+4])
+		w1 := x % n
+		w2 := (x/n + w1) % n
+		w3 := (x/n/n + w2) % n
+		words = append(words, lang.Words[w1], lang.Words[w2], lang.Words[w3])
+	}
+
+	words = append(words, words[lang.checksumIndex(words)])
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToSeed декодирует 24 или 25 слов в seed, определяя словарь автоматически.
+// Для 25 слов проверяется контрольное слово.
+func MnemonicToSeed(mnemonic string) (Key, *MnemonicLanguage, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) != mnemonicDataWords && len(words) != MnemonicWords {
+		return Key{}, nil, fmt.Errorf("%w, got %d", ErrMnemonicLength, len(words))
+	}
+
+	var lastErr error = ErrMnemonicWord
+	for _, lang := range mnemonicLanguages {
+		seed, err := decodeMnemonic(words, lang)
+		if err == nil {
+			return seed, lang, nil
+		}
+		if !errors.Is(err, ErrMnemonicWord) {
+			lastErr = err
+		}
+	}
+	return Key{}, nil, lastErr
+}
+
+// MnemonicToSeedLanguage декодирует мнемонику в заданном словаре
+func MnemonicToSeedLanguage(mnemonic string, lang *MnemonicLanguage) (Key, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) != mnemonicDataWords && len(words) != MnemonicWords {
+		return Key{}, fmt.Errorf("%w, got %d", ErrMnemonicLength, len(words))
+	}
+	return decodeMnemonic(words, lang)
+}
+
+func decodeMnemonic(words []string, lang *MnemonicLanguage) (Key, error) {
+	var seed Key
+	n := uint32(len(lang.Words))
+
+	for i := 0; i < mnemonicDataWords; i += 3 {
+		var idx [3]uint32
+		for j := range idx {
+			k, ok := lang.wordIndex(words[i+j])
+			if !ok {
+				return Key{}, fmt.Errorf("%w %q for %s", ErrMnemonicWord, words[i+j], lang.EnglishName)
+			}
+			idx[j] = uint32(k)
+		}
+
+		// Арифметика uint32 с переполнением, как в Monero
+		w1, w2, w3 := idx[0], idx[1], idx[2]
+		x := w1 + n*((n-w1+w2)%n) + n*n*((n-w2+w3)%n)
+		if x%n != w1 {
+			return Key{}, fmt.Errorf("%w: words %d-%d do not encode a valid value", ErrMnemonicWord, i+1, i+3)
+		}
+		binary.LittleEndian.PutUint32(seed[i/3*4:], x)
+	}
+
+	if len(words) == MnemonicWords {
+		expected := lang.checksumIndex(words[:mnemonicDataWords])
+		if lang.prefix(words[expected]) != lang.prefix(words[mnemonicDataWords]) {
+			return Key{}, ErrMnemonicChecksum
+		}
+	}
+
+	return seed, nil
+}
+
+// ViewKeyFromSpendKey вычисляет секретный ключ просмотра a = sc_reduce32(Keccak256(b))
+func ViewKeyFromSpendKey(privSpendKey *Key) Key {
+	return *HashToScalar(privSpendKey[:])
+}
+
+// WalletKeys - ключи кошелька, восстановленные из seed
+type WalletKeys struct {
+	PrivateSpendKey Key
+	PrivateViewKey  Key
+	PublicSpendKey  Key
+	PublicViewKey   Key
+}
+
+// KeysFromSeed восстанавливает ключи как wallet2: b = sc_reduce32(seed), a = sc_reduce32(Keccak256(b))
+func KeysFromSeed(seed Key) WalletKeys {
+	spend := seed
+	ScReduce32(&spend)
+	view := ViewKeyFromSpendKey(&spend)
+	return WalletKeys{
+		PrivateSpendKey: spend,
+		PrivateViewKey:  view,
+		PublicSpendKey:  *spend.PubKey(),
+		PublicViewKey:   *view.PubKey(),
+	}
+}
+
+// KeysFromMnemonic восстанавливает ключи кошелька из мнемоники
+func KeysFromMnemonic(mnemonic string) (WalletKeys, error) {
+	seed, _, err := MnemonicToSeed(mnemonic)
+	if err != nil {
+		return WalletKeys{}, err
+	}
+	return KeysFromSeed(seed), nil
+}
+
+// PrimaryAddress возвращает основной адрес mainnet
+func (k WalletKeys) PrimaryAddress() string {
+	return k.Address(Mainnet).String()
+}
+
+// Address возвращает основной адрес в сети network
+func (k WalletKeys) Address(network Network) Address {
+	return Address{Network: network, Kind: AddressStandard, PublicSpendKey: k.PublicSpendKey, PublicViewKey: k.PublicViewKey}
+}
+
+// Mnemonic возвращает 25 слов для секретного ключа траты
+func (k WalletKeys) Mnemonic(lang *MnemonicLanguage) (string, error) {
+	return SeedToMnemonic(k.PrivateSpendKey, lang)
+}
@@ -0,0 +1,188 @@
+package util
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+)
+
+// HardFork - версия протокола и высота, с которой она действует
+type HardFork struct {
+	Version uint8  `json:"version"`
+	Height  uint64 `json:"height"`
+}
+
+// NetworkParams - параметры сети из cryptonote_config.h и hardforks.cpp
+type NetworkParams struct {
+	Network   Network
+	NetworkID [16]byte
+	// GenesisHash - hex хеш genesis блока
+	GenesisHash string
+
+	P2PPort uint16
+	RPCPort uint16
+	ZMQPort uint16
+
+	AddressPrefix           byte
+	IntegratedAddressPrefix byte
+	SubaddressPrefix        byte
+
+	HardForks []HardFork
+
+	// Высота и время hard fork v2 - опорная точка оценки высоты по времени (как в wallet2)
+	ForkV2Height uint64
+	ForkV2Time   int64
+}
+
+var MainnetParams = &NetworkParams{
+	Network:     Mainnet,
+	NetworkID:   [16]byte{0x12, 0x30, 0xf1, 0x71, 0x61, 0x04, 0x41, 0x61, 0x17, 0x31, 0x00, 0x82, 0x16, 0xa1, 0xa1, 0x10},
+	GenesisHash: "418015bb9ae982a1975da7d79277c2705727a56894ba0fb246adaabb1f4632e3",
+
+	P2PPort: 18080,
+	RPCPort: 18081,
+	ZMQPort: 18082,
+
+	AddressPrefix:           MainnetAddressPrefix,
+	IntegratedAddressPrefix: MainnetIntegratedPrefix,
+	SubaddressPrefix:        MainnetSubaddressPrefix,
+
+	HardForks: []HardFork{
+		{1, 1}, {2, 1009827}, {3, 1141317}, {4, 1220516}, {5, 1288616}, {6, 1400000},
+		{7, 1546000}, {8, 1685555}, {9, 1686275}, {10, 1788000}, {11, 1788720},
+		{12, 1978433}, {13, 2210000}, {14, 2210720}, {15, 2688888}, {16, 2689608},
+	},
+
+	ForkV2Height: 1009827,
+	ForkV2Time:   1458748658,
+}
+
+var TestnetParams = &NetworkParams{
+	Network:     Testnet,
+	NetworkID:   [16]byte{0x12, 0x30, 0xf1, 0x71, 0x61, 0x04, 0x41, 0x61, 0x17, 0x31, 0x00, 0x82, 0x16, 0xa1, 0xa1, 0x11},
+	GenesisHash: "48ca7cd3c8de5b6a4d53d2861fbdaedca141553559f9be9520068053cda8430b",
+
+	P2PPort: 28080,
+	RPCPort: 28081,
+	ZMQPort: 28082,
+
+	AddressPrefix:           TestnetAddressPrefix,
+	IntegratedAddressPrefix: TestnetIntegratedPrefix,
+	SubaddressPrefix:        TestnetSubaddressPrefix,
+
+	HardForks: []HardFork{
+		{1, 1}, {2, 624634}, {3, 800500}, {4, 801219}, {5, 802660}, {6, 971400},
+		{7, 1057027}, {8, 1057058}, {9, 1057778}, {10, 1154318}, {11, 1155038},
+		{12, 1308737}, {13, 1543939}, {14, 1544659}, {15, 1982800}, {16, 1983520},
+	},
+
+	ForkV2Height: 624634,
+	ForkV2Time:   1448285909,
+}
+
+var StagenetParams = &NetworkParams{
+	Network:     Stagenet,
+	NetworkID:   [16]byte{0x12, 0x30, 0xf1, 0x71, 0x61, 0x04, 0x41, 0x61, 0x17, 0x31, 0x00, 0x82, 0x16, 0xa1, 0xa1, 0x12},
+	GenesisHash: "76ee3cc98646292206cd3e86f74d88b4dcc1d937088645e9b0cbca84b7ce74eb",
+
+	P2PPort: 38080,
+	RPCPort: 38081,
+	ZMQPort: 38082,
+
+	AddressPrefix:           StagenetAddressPrefix,
+	IntegratedAddressPrefix: StagenetIntegratedPrefix,
+	SubaddressPrefix:        StagenetSubaddressPrefix,
+
+	HardForks: []HardFork{
+		{1, 1}, {2, 32000}, {3, 33000}, {4, 34000}, {5, 35000}, {6, 36000},
+		{7, 37000}, {8, 176456}, {9, 177176}, {10, 269000}, {11, 269720},
+		{12, 454721}, {13, 675405}, {14, 676125}, {15, 1151000}, {16, 1151720},
+	},
+
+	ForkV2Height: 32000,
+	ForkV2Time:   1520937818,
+}
+
+// RegtestParams возвращает параметры демона, запущенного с --regtest:
+// сеть и адреса mainnet, но последняя версия протокола действует с высоты 1
+func RegtestParams() *NetworkParams {
+	params := *MainnetParams
+	params.HardForks = []HardFork{
+		{1, 0},
+		{MainnetParams.HardForks[len(MainnetParams.HardForks)-1].Version, 1},
+	}
+	// Цепочка regtest начинается с нуля, оценка высоты по времени не нужна
+	params.ForkV2Height = 0
+	params.ForkV2Time = math.MaxInt64
+	return &params
+}
+
+// Params возвращает параметры сети
+func (n Network) Params() *NetworkParams {
+	switch n {
+	case Testnet:
+		return TestnetParams
+	case Stagenet:
+		return StagenetParams
+	default:
+		return MainnetParams
+	}
+}
+
+var networks = []Network{Mainnet, Testnet, Stagenet}
+
+// AddressPrefixFor возвращает префикс адреса вида kind
+func (p *NetworkParams) AddressPrefixFor(kind AddressKind) (byte, error) {
+	switch kind {
+	case AddressStandard:
+		return p.AddressPrefix, nil
+	case AddressIntegrated:
+		return p.IntegratedAddressPrefix, nil
+	case AddressSubaddress:
+		return p.SubaddressPrefix, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown %s %s", ErrInvalidAddress, p.Network, kind)
+	}
+}
+
+// GenesisHashBytes возвращает хеш genesis блока
+func (p *NetworkParams) GenesisHashBytes() []byte {
+	b, _ := hex.DecodeString(p.GenesisHash)
+	return b
+}
+
+// HardForkVersion возвращает версию протокола на высоте height
+func (p *NetworkParams) HardForkVersion(height uint64) uint8 {
+	version := uint8(1)
+	for _, fork := range p.HardForks {
+		if height >= fork.Height {
+			version = fork.Version
+		}
+	}
+	return version
+}
+
+// HardForkHeight возвращает высоту, с которой действует версия version
+func (p *NetworkParams) HardForkHeight(version uint8) (uint64, bool) {
+	for _, fork := range p.HardForks {
+		if fork.Version == version {
+			return fork.Height, true
+		}
+	}
+	return 0, false
+}
+
+// RestoreHeight оценивает высоту на момент t с запасом в неделю
+func (p *NetworkParams) RestoreHeight(t time.Time) uint64 {
+	unix := t.Unix()
+	if unix <= p.ForkV2Time {
+		return 0
+	}
+
+	height := p.ForkV2Height + uint64(unix-p.ForkV2Time)/difficultyTarget
+	if height <= restoreHeightMargin {
+		return 0
+	}
+	return height - restoreHeightMargin
+}
@@ -0,0 +1,314 @@
+package util
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Polyseed - 16-словный seed с датой создания кошелька (github.com/tevador/polyseed).
+// 15 слов несут 150 бит секрета, 5 бит флагов и 10 бит даты,
+// 16-е слово - контрольная сумма (многочлен над GF(2^11)).
+const (
+	PolyseedWords = 16
+
+	// PolyseedCoinMonero - идентификатор монеты, смешиваемый в контрольную сумму и KDF
+	PolyseedCoinMonero = 0
+
+	// PolyseedEpoch - 1 ноября 2021 12:00 UTC, нулевая дата
+	PolyseedEpoch = 1635768000
+	// PolyseedTimeStep - шаг даты, 1/12 григорианского года
+	PolyseedTimeStep = 2629746
+
+	polyseedSecretBits  = 150
+	polyseedSecretSize  = (polyseedSecretBits + 7) / 8
+	polyseedDateBits    = 10
+	polyseedDateMask    = 1<<polyseedDateBits - 1
+	polyseedFeatureBits = 5
+	polyseedShareBits   = 10
+	polyseedDataWords   = PolyseedWords - 1
+	polyseedGFBits      = 11
+	polyseedGFMask      = 1<<polyseedGFBits - 1
+	polyseedKDFRounds   = 10000
+	polyseedPrefixLen   = 4
+
+	// PolyseedEncryptedFeature - seed зашифрован паролем
+	PolyseedEncryptedFeature = 16
+	// PolyseedUserFeatures - биты флагов, доступные приложению
+	PolyseedUserFeatures = 7
+)
+
+var (
+	ErrPolyseedLength      = errors.New("polyseed must have 16 words")
+	ErrPolyseedWord        = errors.New("unknown polyseed word")
+	ErrPolyseedChecksum    = errors.New("invalid polyseed checksum")
+	ErrPolyseedFeatures    = errors.New("unsupported polyseed features")
+	ErrPolyseedEncrypted   = errors.New("encrypted polyseed is not supported")
+	ErrPolyseedSecretRange = errors.New("polyseed secret has bits beyond 150")
+)
+
+// Polyseed - расшифрованные данные seed
+type Polyseed struct {
+	// Secret - 150 бит секрета, последний байт использует только младшие 6 бит
+	Secret [polyseedSecretSize]byte
+	// Birthday - дата в шагах PolyseedTimeStep от PolyseedEpoch
+	Birthday uint16
+	Features uint8
+}
+
+// NewPolyseed создаёт seed со случайным секретом и датой created
+func NewPolyseed(created time.Time, features uint8) (*Polyseed, error) {
+	if features&^PolyseedUserFeatures != 0 {
+		return nil, fmt.Errorf("%w: %#x", ErrPolyseedFeatures, features)
+	}
+
+	seed := &Polyseed{
+		Birthday: PolyseedBirthday(created),
+		Features: features,
+	}
+	if _, err := rand.Read(seed.Secret[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate polyseed secret: %w", err)
+	}
+	seed.Secret[polyseedSecretSize-1] &= 0x3f
+	return seed, nil
+}
+
+// PolyseedBirthday кодирует время в дату Polyseed; до PolyseedEpoch - 0
+func PolyseedBirthday(t time.Time) uint16 {
+	unix := t.Unix()
+	if unix < PolyseedEpoch {
+		return 0
+	}
+	return uint16((uint64(unix)-PolyseedEpoch)/PolyseedTimeStep) & polyseedDateMask
+}
+
+// BirthdayTime возвращает начало шага даты создания
+func (p *Polyseed) BirthdayTime() time.Time {
+	return time.Unix(PolyseedEpoch+int64(p.Birthday)*PolyseedTimeStep, 0).UTC()
+}
+
+// RestoreHeight возвращает высоту mainnet, с которой достаточно сканировать цепочку
+func (p *Polyseed) RestoreHeight() uint64 {
+	return RestoreHeightFromTime(p.BirthdayTime())
+}
+
+// RestoreHeightFor возвращает высоту начала сканирования в сети params
+func (p *Polyseed) RestoreHeightFor(params *NetworkParams) uint64 {
+	return params.RestoreHeight(p.BirthdayTime())
+}
+
+// IsEncrypted сообщает, что seed зашифрован паролем
+func (p *Polyseed) IsEncrypted() bool {
+	return p.Features&PolyseedEncryptedFeature != 0
+}
+
+// Encode возвращает 16 слов (английский словарь BIP-39)
+func (p *Polyseed) Encode() (string, error) {
+	coeffs, err := p.toPoly()
+	if err != nil {
+		return "", err
+	}
+
+	coeffs[0] = polyseedEval(coeffs)
+	coeffs[1] ^= PolyseedCoinMonero
+
+	words := make([]string, PolyseedWords)
+	for i, c := range coeffs {
+		words[i] = bip39EnglishWords[c]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// DecodePolyseed разбирает 16 слов; слова можно сокращать до 4 букв
+func DecodePolyseed(phrase string) (*Polyseed, error) {
+	words := strings.Fields(strings.ToLower(phrase))
+	if len(words) != PolyseedWords {
+		return nil, fmt.Errorf("%w, got %d", ErrPolyseedLength, len(words))
+	}
+
+	var coeffs [PolyseedWords]uint16
+	for i, w := range words {
+		idx, ok := polyseedWordIndex(w)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrPolyseedWord, w)
+		}
+		coeffs[i] = idx
+	}
+
+	coeffs[1] ^= PolyseedCoinMonero
+	if polyseedEval(coeffs) != 0 {
+		return nil, ErrPolyseedChecksum
+	}
+
+	seed := fromPoly(coeffs)
+	if seed.Features&^(PolyseedUserFeatures|PolyseedEncryptedFeature) != 0 {
+		return nil, fmt.Errorf("%w: %#x", ErrPolyseedFeatures, seed.Features)
+	}
+	return seed, nil
+}
+
+// SpendKey выводит секретный ключ траты:
+// sc_reduce32(PBKDF2-SHA256(secret, "POLYSEED key" || coin || birthday || features))
+func (p *Polyseed) SpendKey() (Key, error) {
+	if p.IsEncrypted() {
+		return Key{}, ErrPolyseedEncrypted
+	}
+
+	var salt [32]byte
+	copy(salt[:], "POLYSEED key")
+	salt[13], salt[14], salt[15] = 0xff, 0xff, 0xff
+	binary.LittleEndian.PutUint32(salt[16:], PolyseedCoinMonero)
+	binary.LittleEndian.PutUint32(salt[20:], uint32(p.Birthday))
+	binary.LittleEndian.PutUint32(salt[24:], uint32(p.Features))
+
+	var secret [32]byte
+	copy(secret[:], p.Secret[:])
+
+	derived, err := pbkdf2.Key(sha256.New, string(secret[:]), salt[:], polyseedKDFRounds, KeyLength)
+	if err != nil {
+		return Key{}, fmt.Errorf("failed to derive polyseed key: %w", err)
+	}
+
+	key := Key(derived)
+	ScReduce32(&key)
+	return key, nil
+}
+
+// Keys восстанавливает ключи кошелька; ключ просмотра выводится из ключа траты
+func (p *Polyseed) Keys() (WalletKeys, error) {
+	spend, err := p.SpendKey()
+	if err != nil {
+		return WalletKeys{}, err
+	}
+	return KeysFromSeed(spend), nil
+}
+
+// toPoly раскладывает секрет и дополнительные биты по 15 словам данных:
+// в каждом слове 10 бит секрета (старшие первыми) и 1 бит флагов/даты
+func (p *Polyseed) toPoly() ([PolyseedWords]uint16, error) {
+	var coeffs [PolyseedWords]uint16
+	if p.Secret[polyseedSecretSize-1]&0xc0 != 0 {
+		return coeffs, ErrPolyseedSecretRange
+	}
+	if p.Birthday > polyseedDateMask || p.Features >= 1<<polyseedFeatureBits {
+		return coeffs, fmt.Errorf("%w: birthday %d, features %#x", ErrPolyseedFeatures, p.Birthday, p.Features)
+	}
+
+	extra := uint32(p.Features)<<polyseedDateBits | uint32(p.Birthday)
+	extraBits := polyseedFeatureBits + polyseedDateBits
+
+	bit := 0
+	for i := 0; i < polyseedDataWords; i++ {
+		var word uint16
+		for j := 0; j < polyseedShareBits; j++ {
+			word = word<<1 | uint16(p.secretBit(bit))
+			bit++
+		}
+		extraBits--
+		coeffs[1+i] = word<<1 | uint16(extra>>extraBits&1)
+	}
+	return coeffs, nil
+}
+
+func fromPoly(coeffs [PolyseedWords]uint16) *Polyseed {
+	seed := &Polyseed{}
+	var extra uint32
+
+	bit := 0
+	for i := 1; i < PolyseedWords; i++ {
+		word := coeffs[i]
+		extra = extra<<1 | uint32(word&1)
+		word >>= 1
+		for j := polyseedShareBits - 1; j >= 0; j-- {
+			seed.setSecretBit(bit, byte(word>>j&1))
+			bit++
+		}
+	}
+
+	seed.Birthday = uint16(extra & polyseedDateMask)
+	seed.Features = uint8(extra >> polyseedDateBits)
+	return seed
+}
+
+// secretBit возвращает n-й бит потока секрета: байты 0..17 по 8 бит,
+// последний байт - младшие 6 бит, в каждом байте старшие биты первыми
+func (p *Polyseed) secretBit(n int) byte {
+	byteIdx, width := n/8, 8
+	if byteIdx == polyseedSecretSize-1 {
+		width = polyseedSecretBits - 8*(polyseedSecretSize-1)
+	}
+	return p.Secret[byteIdx] >> (width - 1 - n%8) & 1
+}
+
+func (p *Polyseed) setSecretBit(n int, v byte) {
+	byteIdx, width := n/8, 8
+	if byteIdx == polyseedSecretSize-1 {
+		width = polyseedSecretBits - 8*(polyseedSecretSize-1)
+	}
+	p.Secret[byteIdx] |= v << (width - 1 - n%8)
+}
+
+// polyseedEval вычисляет значение многочлена в точке x = 2 (схема Горнера)
+func polyseedEval(coeffs [PolyseedWords]uint16) uint16 {
+	result := coeffs[PolyseedWords-1]
+	for i := PolyseedWords - 2; i >= 0; i-- {
+		result = polyseedMul2(result) ^ coeffs[i]
+	}
+	return result
+}
+
+// polyseedMul2 умножает на x в GF(2^11) по модулю x^11 + x^2 + 1
+func polyseedMul2(x uint16) uint16 {
+	x <<= 1
+	if x > polyseedGFMask {
+		x ^= 0x805
+	}
+	return x
+}
+
+var (
+	polyseedWordsOnce sync.Once
+	polyseedPrefixes  map[string]uint16
+)
+
+func polyseedWordIndex(word string) (uint16, bool) {
+	polyseedWordsOnce.Do(func() {
+		polyseedPrefixes = make(map[string]uint16, len(bip39EnglishWords))
+		for i, w := range bip39EnglishWords {
+			polyseedPrefixes[polyseedPrefix(w)] = uint16(i)
+		}
+	})
+
+	idx, ok := polyseedPrefixes[polyseedPrefix(word)]
+	return idx, ok
+}
+
+func polyseedPrefix(word string) string {
+	if len(word) <= polyseedPrefixLen {
+		return word
+	}
+	return word[:polyseedPrefixLen]
+}
+
+// IsPolyseed быстро отличает Polyseed (16 слов) от 25-словной мнемоники
+func IsPolyseed(phrase string) bool {
+	return len(strings.Fields(phrase)) == PolyseedWords
+}
+
+const (
+	// Целевое время блока в секундах
+	difficultyTarget = 120
+	// restoreHeightMargin - запас в блоках при оценке высоты (неделя)
+	restoreHeightMargin = 7 * 24 * 3600 / difficultyTarget
+)
+
+// RestoreHeightFromTime оценивает высоту mainnet на момент t с запасом в неделю
+func RestoreHeightFromTime(t time.Time) uint64 {
+	return MainnetParams.RestoreHeight(t)
+}
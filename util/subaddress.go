@@ -0,0 +1,154 @@
+package util
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// SubaddressIndex - индекс субадреса (major - аккаунт, minor - адрес в аккаунте).
+// Индекс {0, 0} соответствует основному адресу.
+type SubaddressIndex struct {
+	Major uint32 `json:"major"`
+	Minor uint32 `json:"minor"`
+}
+
+func (i SubaddressIndex) IsPrimary() bool {
+	return i.Major == 0 && i.Minor == 0
+}
+
+// SubaddressSecretKey вычисляет m = Hs("SubAddr\0" || a || major || minor)
+func SubaddressSecretKey(privViewKey *Key, index SubaddressIndex) Key {
+	data := make([]byte, 0, 8+KeyLength+8)
+	data = append(data, []byte("SubAddr\x00")...)
+	data = append(data, privViewKey[:]...)
+	data = binary.LittleEndian.AppendUint32(data, index.Major)
+	data = binary.LittleEndian.AppendUint32(data, index.Minor)
+	return *HashToScalar(data)
+}
+
+// SubaddressSpendKey вычисляет публичный ключ траты субадреса D = B + m*G
+func SubaddressSpendKey(privViewKey, pubSpendKey *Key, index SubaddressIndex) Key {
+	if index.IsPrimary() {
+		return *pubSpendKey
+	}
+
+	m := SubaddressSecretKey(privViewKey, index)
+	var D Key
+	AddKeys(&D, pubSpendKey, m.PubKey())
+	return D
+}
+
+// SubaddressKeys возвращает публичные ключи субадреса: D = B + m*G, C = a*D
+func SubaddressKeys(privViewKey, pubSpendKey *Key, index SubaddressIndex) (spend Key, view Key) {
+	spend = SubaddressSpendKey(privViewKey, pubSpendKey, index)
+	if index.IsPrimary() {
+		view = *privViewKey.PubKey()
+		return
+	}
+	view = ScalarMult(privViewKey, &spend)
+	return
+}
+
+// Subaddress возвращает base58 адрес mainnet для индекса; для {0, 0} - основной адрес
+func Subaddress(privViewKey, pubSpendKey *Key, index SubaddressIndex) string {
+	return SubaddressAddress(Mainnet, privViewKey, pubSpendKey, index).String()
+}
+
+// SubaddressAddress возвращает адрес сети network для индекса; для {0, 0} - основной адрес
+func SubaddressAddress(network Network, privViewKey, pubSpendKey *Key, index SubaddressIndex) Address {
+	spend, view := SubaddressKeys(privViewKey, pubSpendKey, index)
+	kind := AddressSubaddress
+	if index.IsPrimary() {
+		kind = AddressStandard
+	}
+	return Address{Network: network, Kind: kind, PublicSpendKey: spend, PublicViewKey: view}
+}
+
+// DeriveSubaddressPublicKey вычисляет D = P - Hs(derivation || index)*G,
+// т.е. публичный ключ траты, на который был отправлен выход P
+func DeriveSubaddressPublicKey(outKey *Key, derivation *Key, outIndex uint64) (Key, bool) {
+	var result Key
+	if !new(ExtendedGroupElement).FromBytes(outKey) {
+		return result, false
+	}
+
+	scalar := derivationToScalar(derivation, outIndex)
+	SubKeys(&result, outKey, scalar.PubKey())
+	return result, true
+}
+
+// SubaddressTable - предвычисленная таблица публичный ключ траты -> индекс субадреса.
+// Безопасна для конкурентного использования.
+type SubaddressTable struct {
+	mu          sync.RWMutex
+	privViewKey Key
+	pubSpendKey Key
+	network     Network
+	entries     map[Key]SubaddressIndex
+}
+
+// NewSubaddressTable создаёт таблицу, содержащую основной адрес {0, 0}
+func NewSubaddressTable(privViewKey, pubSpendKey Key) *SubaddressTable {
+	t := &SubaddressTable{
+		privViewKey: privViewKey,
+		pubSpendKey: pubSpendKey,
+		entries:     make(map[Key]SubaddressIndex),
+	}
+	t.Add(SubaddressIndex{})
+	return t
+}
+
+// Add добавляет индекс в таблицу и возвращает его публичный ключ траты
+func (t *SubaddressTable) Add(index SubaddressIndex) Key {
+	spend := SubaddressSpendKey(&t.privViewKey, &t.pubSpendKey, index)
+
+	t.mu.Lock()
+	t.entries[spend] = index
+	t.mu.Unlock()
+
+	return spend
+}
+
+// AddRange добавляет субадреса major/0 ... major/(count-1)
+func (t *SubaddressTable) AddRange(major uint32, count uint32) {
+	for minor := uint32(0); minor < count; minor++ {
+		t.Add(SubaddressIndex{Major: major, Minor: minor})
+	}
+}
+
+// Lookup ищет индекс субадреса по публичному ключу траты
+func (t *SubaddressTable) Lookup(spendKey Key) (SubaddressIndex, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	index, ok := t.entries[spendKey]
+	return index, ok
+}
+
+func (t *SubaddressTable) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.entries)
+}
+
+// SetNetwork задаёт сеть, для которой Address кодирует адреса (по умолчанию mainnet)
+func (t *SubaddressTable) SetNetwork(network Network) {
+	t.mu.Lock()
+	t.network = network
+	t.mu.Unlock()
+}
+
+// Address возвращает base58 адрес для индекса
+func (t *SubaddressTable) Address(index SubaddressIndex) string {
+	t.mu.RLock()
+	network := t.network
+	t.mu.RUnlock()
+	return SubaddressAddress(network, &t.privViewKey, &t.pubSpendKey, index).String()
+}
+
+func (t *SubaddressTable) PrivateViewKey() Key {
+	return t.privViewKey
+}
+
+func (t *SubaddressTable) PublicSpendKey() Key {
+	return t.pubSpendKey
+}
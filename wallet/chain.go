@@ -0,0 +1,156 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/0xAF4/go-monero/types"
+)
+
+// DefaultChainWindow - сколько последних id блоков хранит ChainTracker
+const DefaultChainWindow = 1000
+
+var (
+	// ErrBlockNotLinked - PreviousBlockHash блока не совпадает с вершиной; вершина отсоединена,
+	// общий предок ищется следующим запросом с разреженной историей
+	ErrBlockNotLinked = errors.New("block does not link to chain top")
+	// ErrReorgTooDeep - общий предок ниже окна ChainTracker, нужно пересканирование с нуля
+	ErrReorgTooDeep = errors.New("reorg is deeper than tracked window")
+)
+
+// ChainTracker хранит id последних блоков и проверяет, что новые блоки продолжают цепочку.
+// Не безопасен для конкурентного использования; Scanner вызывает его под своей блокировкой.
+type ChainTracker struct {
+	window int
+	// base - высота ids[0]
+	base uint64
+	ids  []types.Hash
+}
+
+func NewChainTracker(window int) *ChainTracker {
+	if window < 2 {
+		window = 2
+	}
+	return &ChainTracker{window: window}
+}
+
+func (c *ChainTracker) Len() int {
+	return len(c.ids)
+}
+
+// Base возвращает минимальную высоту в окне
+func (c *ChainTracker) Base() uint64 {
+	return c.base
+}
+
+// Top возвращает высоту и id последнего блока
+func (c *ChainTracker) Top() (uint64, types.Hash, bool) {
+	if len(c.ids) == 0 {
+		return 0, types.Hash{}, false
+	}
+	return c.base + uint64(len(c.ids)) - 1, c.ids[len(c.ids)-1], true
+}
+
+// Id возвращает id блока на высоте height, если он в окне
+func (c *ChainTracker) Id(height uint64) (types.Hash, bool) {
+	if height < c.base || height >= c.base+uint64(len(c.ids)) {
+		return types.Hash{}, false
+	}
+	return c.ids[height-c.base], true
+}
+
+// Connect добавляет блок height с id и prev. Блок на уже известной высоте с другим id
+// отсоединяет эту высоту и всё выше; отсоединённые id возвращаются от старых к новым.
+// Если prev не совпадает с вершиной, вершина отсоединяется и возвращается ErrBlockNotLinked.
+func (c *ChainTracker) Connect(height uint64, id, prev types.Hash) ([]types.Hash, error) {
+	if len(c.ids) == 0 {
+		c.base = height
+		c.ids = append(c.ids, id)
+		return nil, nil
+	}
+
+	if height < c.base {
+		return nil, fmt.Errorf("%w: block %d is below %d", ErrReorgTooDeep, height, c.base)
+	}
+
+	var detached []types.Hash
+	if known, ok := c.Id(height); ok {
+		if known == id {
+			return nil, nil
+		}
+		if height == c.base {
+			return nil, fmt.Errorf("%w: block %d replaces window base", ErrReorgTooDeep, height)
+		}
+		detached = c.Detach(height)
+	}
+
+	top, topId, _ := c.Top()
+	if height != top+1 {
+		return detached, fmt.Errorf("block %d does not follow chain top %d", height, top)
+	}
+	if prev != topId {
+		if len(c.ids) == 1 {
+			return detached, fmt.Errorf("%w: block %d does not link to %x", ErrReorgTooDeep, height, topId)
+		}
+		detached = append(c.Detach(top), detached...)
+		return detached, fmt.Errorf("%w: block %d prev %x, top %x", ErrBlockNotLinked, height, prev, topId)
+	}
+
+	c.ids = append(c.ids, id)
+	if len(c.ids) > c.window {
+		drop := len(c.ids) - c.window
+		c.ids = append(c.ids[:0:0], c.ids[drop:]...)
+		c.base += uint64(drop)
+	}
+	return detached, nil
+}
+
+// Detach убирает блоки с высоты height и выше и возвращает их id
+func (c *ChainTracker) Detach(height uint64) []types.Hash {
+	if height < c.base {
+		height = c.base
+	}
+	if height >= c.base+uint64(len(c.ids)) {
+		return nil
+	}
+
+	detached := append([]types.Hash{}, c.ids[height-c.base:]...)
+	c.ids = c.ids[:height-c.base]
+	return detached
+}
+
+func (c *ChainTracker) restore(base uint64, ids []types.Hash) {
+	if len(ids) > c.window {
+		base += uint64(len(ids) - c.window)
+		ids = ids[len(ids)-c.window:]
+	}
+	c.base = base
+	c.ids = append([]types.Hash{}, ids...)
+}
+
+// BlockIds возвращает разреженную историю окна для BlocksRequest.BlockIds, последним - genesis
+func (c *ChainTracker) BlockIds(genesis types.Hash) []types.Hash {
+	top, _, ok := c.Top()
+	if !ok {
+		return []types.Hash{genesis}
+	}
+
+	ids := []types.Hash{}
+	for _, height := range types.ChainHistoryHeights(top) {
+		if id, ok := c.Id(height); ok {
+			ids = append(ids, id)
+		}
+	}
+	if ids[len(ids)-1] != genesis {
+		ids = append(ids, genesis)
+	}
+	return ids
+}
+
+func blockId(block *types.Block) types.Hash {
+	var id types.Hash
+	raw, _ := hex.DecodeString(block.GetBlockId())
+	copy(id[:], raw)
+	return id
+}
@@ -0,0 +1,47 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+// Output - выход кошелька, найденный сканером
+type Output struct {
+	types.OwnedOutput
+	// GlobalIndex - глобальный индекс выхода (для колец и get_outs)
+	GlobalIndex uint64 `json:"global_index"`
+	// KeyImage - образ ключа; вычисляется только при известном ключе траты
+	KeyImage    types.Hash `json:"key_image"`
+	HasKeyImage bool       `json:"has_key_image"`
+	Coinbase    bool       `json:"coinbase"`
+
+	Spent       bool       `json:"spent"`
+	SpentHeight uint64     `json:"spent_height"`
+	SpentTxHash types.Hash `json:"spent_tx_hash"`
+}
+
+// Transfer - запись истории: сколько кошелёк получил и потратил в транзакции
+type Transfer struct {
+	TxHash   types.Hash  `json:"tx_hash"`
+	Height   uint64      `json:"height"`
+	Received util.Amount `json:"received"`
+	Spent    util.Amount `json:"spent"`
+}
+
+// Balance - баланс кошелька; Locked - ещё не разблокированные выходы
+type Balance struct {
+	Total    util.Amount `json:"total"`
+	Unlocked util.Amount `json:"unlocked"`
+	Locked   util.Amount `json:"locked"`
+}
+
+func (b *Balance) add(o *Output, chainHeight uint64, now time.Time) {
+	b.Total += o.Amount
+	if o.IsUnlocked(chainHeight, now) {
+		b.Unlocked += o.Amount
+	} else {
+		b.Locked += o.Amount
+	}
+}
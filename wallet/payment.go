@@ -0,0 +1,231 @@
+package wallet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+// DefaultConfirmationDepths - глубины подтверждений по умолчанию для PaymentTracker
+var DefaultConfirmationDepths = []uint64{1, 10, 20}
+
+// HeightDaemon - метод демона, нужный PaymentTracker
+type HeightDaemon interface {
+	GetHeightCtx(ctx context.Context) (string, uint64, error)
+}
+
+// Payment - отслеживаемая транзакция кошелька
+type Payment struct {
+	TxHash    types.Hash
+	PaymentID uint64
+	// Height - высота блока с транзакцией
+	Height uint64
+	// Received/Spent - сколько кошелёк получил и потратил в транзакции
+	Received util.Amount
+	Spent    util.Amount
+}
+
+// PaymentEvent - платёж достиг глубины Depth или, при Unconfirmed, был откачен
+// (блок с ним отсоединён или транзакция попала в другой блок)
+type PaymentEvent struct {
+	Payment
+	Depth         uint64
+	Confirmations uint64
+	Unconfirmed   bool
+}
+
+type paymentState struct {
+	payment Payment
+	// reached - глубины, о которых уже сообщено
+	reached map[uint64]bool
+}
+
+// PaymentTracker следит за подтверждениями транзакций по txid или payment ID.
+// Платежи берутся из Scanner, поэтому Update нужно вызывать после Scanner.Sync.
+type PaymentTracker struct {
+	mu sync.Mutex
+
+	daemon  HeightDaemon
+	scanner *Scanner
+	depths  []uint64
+	handler func(PaymentEvent)
+
+	txs        map[types.Hash]bool
+	paymentIds map[uint64]bool
+	states     map[types.Hash]*paymentState
+}
+
+// NewPaymentTracker создаёт трекер; handler вызывается при достижении каждой из depths
+// (по умолчанию DefaultConfirmationDepths) и при откате уже подтверждённого платежа.
+// Глубина 0 срабатывает, как только платёж найден в блоке.
+func NewPaymentTracker(daemon HeightDaemon, scanner *Scanner, depths []uint64, handler func(PaymentEvent)) *PaymentTracker {
+	if len(depths) == 0 {
+		depths = DefaultConfirmationDepths
+	}
+	depths = slices.Clone(depths)
+	slices.Sort(depths)
+
+	return &PaymentTracker{
+		daemon:     daemon,
+		scanner:    scanner,
+		depths:     slices.Compact(depths),
+		handler:    handler,
+		txs:        make(map[types.Hash]bool),
+		paymentIds: make(map[uint64]bool),
+		states:     make(map[types.Hash]*paymentState),
+	}
+}
+
+// WatchTx добавляет транзакцию (входящую или исходящую) в отслеживаемые
+func (p *PaymentTracker) WatchTx(hash types.Hash) {
+	p.mu.Lock()
+	p.txs[hash] = true
+	p.mu.Unlock()
+}
+
+// WatchPaymentID отслеживает все входящие платежи с payment ID
+func (p *PaymentTracker) WatchPaymentID(paymentID uint64) {
+	p.mu.Lock()
+	p.paymentIds[paymentID] = true
+	p.mu.Unlock()
+}
+
+func (p *PaymentTracker) UnwatchTx(hash types.Hash) {
+	p.mu.Lock()
+	delete(p.txs, hash)
+	delete(p.states, hash)
+	p.mu.Unlock()
+}
+
+func (p *PaymentTracker) UnwatchPaymentID(paymentID uint64) {
+	p.mu.Lock()
+	delete(p.paymentIds, paymentID)
+	p.mu.Unlock()
+}
+
+// Update запрашивает высоту демона и вызывает handler для новых глубин и откатов.
+// Подтверждения считаются только по блокам, которые проверил сканер: иначе reorg выше
+// его высоты мог бы засчитать платёж, блок которого уже заменён.
+func (p *PaymentTracker) Update(ctx context.Context) error {
+	_, height, err := p.daemon.GetHeightCtx(ctx)
+	if err != nil {
+		return fmt.Errorf("get height: %w", err)
+	}
+	top := min(height, p.scanner.Height())
+
+	p.mu.Lock()
+	events := p.update(top)
+	handler := p.handler
+	p.mu.Unlock()
+
+	if handler != nil {
+		for _, event := range events {
+			handler(event)
+		}
+	}
+	return nil
+}
+
+// Payments возвращает отслеживаемые платежи, найденные сканером
+func (p *PaymentTracker) Payments() []Payment {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payments := p.collect()
+	result := make([]Payment, 0, len(payments))
+	for _, payment := range payments {
+		result = append(result, payment)
+	}
+	slices.SortFunc(result, func(a, b Payment) int {
+		return cmp.Compare(a.Height, b.Height)
+	})
+	return result
+}
+
+// collect собирает отслеживаемые платежи из состояния сканера
+func (p *PaymentTracker) collect() map[types.Hash]Payment {
+	payments := map[types.Hash]Payment{}
+
+	if len(p.txs) != 0 {
+		for _, transfer := range p.scanner.History() {
+			if p.txs[transfer.TxHash] {
+				payments[transfer.TxHash] = Payment{
+					TxHash:   transfer.TxHash,
+					Height:   transfer.Height,
+					Received: transfer.Received,
+					Spent:    transfer.Spent,
+				}
+			}
+		}
+	}
+
+	if len(p.paymentIds) != 0 {
+		for _, out := range p.scanner.Outputs() {
+			if out.PaymentID == 0 || !p.paymentIds[out.PaymentID] || p.txs[out.TxHash] {
+				continue
+			}
+			payment := payments[out.TxHash]
+			payment.TxHash = out.TxHash
+			payment.PaymentID = out.PaymentID
+			payment.Height = out.BlockHeight
+			payment.Received += out.Amount
+			payments[out.TxHash] = payment
+		}
+	}
+	return payments
+}
+
+func (p *PaymentTracker) update(top uint64) []PaymentEvent {
+	payments := p.collect()
+	events := []PaymentEvent{}
+
+	for hash, state := range p.states {
+		if payment, ok := payments[hash]; ok && payment.Height == state.payment.Height {
+			state.payment = payment
+			continue
+		}
+		if len(state.reached) != 0 {
+			events = append(events, PaymentEvent{Payment: state.payment, Unconfirmed: true})
+		}
+		delete(p.states, hash)
+	}
+
+	for hash, payment := range payments {
+		state, ok := p.states[hash]
+		if !ok {
+			state = &paymentState{payment: payment, reached: map[uint64]bool{}}
+			p.states[hash] = state
+		}
+
+		confirmations := uint64(0)
+		if top > payment.Height {
+			confirmations = top - payment.Height
+		}
+		for _, depth := range p.depths {
+			if !state.reached[depth] && confirmations >= depth {
+				state.reached[depth] = true
+				events = append(events, PaymentEvent{Payment: payment, Depth: depth, Confirmations: confirmations})
+			}
+		}
+	}
+
+	slices.SortFunc(events, func(a, b PaymentEvent) int {
+		if a.Unconfirmed != b.Unconfirmed {
+			// Откаты сообщаются раньше новых подтверждений
+			if a.Unconfirmed {
+				return -1
+			}
+			return 1
+		}
+		if c := cmp.Compare(a.Height, b.Height); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Depth, b.Depth)
+	})
+	return events
+}
@@ -0,0 +1,264 @@
+package wallet
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/0xAF4/go-monero/rpc"
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+// PoolDaemon - методы демона, нужные PoolWatcher
+type PoolDaemon interface {
+	GetTransactionPoolHashesCtx(ctx context.Context) ([]types.Hash, error)
+	GetTransactionsCtx(ctx context.Context, txIds []string) ([]types.TxEntry, error)
+}
+
+var _ PoolDaemon = (*rpc.Client)(nil)
+
+// PoolEventKind - тип события пула
+type PoolEventKind int
+
+const (
+	// PoolSeen - транзакция с выходами кошелька появилась в пуле (0-conf)
+	PoolSeen PoolEventKind = iota
+	// PoolConfirmed - транзакция попала в блок Height
+	PoolConfirmed
+	// PoolEvicted - транзакция ушла из пула, не попав в блок
+	PoolEvicted
+	// PoolDoubleSpend - демон видел другую транзакцию с теми же образами ключей
+	PoolDoubleSpend
+)
+
+func (k PoolEventKind) String() string {
+	switch k {
+	case PoolSeen:
+		return "seen"
+	case PoolConfirmed:
+		return "confirmed"
+	case PoolEvicted:
+		return "evicted"
+	case PoolDoubleSpend:
+		return "double spend"
+	default:
+		return fmt.Sprintf("PoolEventKind(%d)", int(k))
+	}
+}
+
+// PoolEvent - событие по транзакции с выходами кошелька Wallet
+type PoolEvent struct {
+	Kind    PoolEventKind
+	Wallet  string
+	TxHash  types.Hash
+	Outputs []types.OwnedOutput
+	// Height - высота блока для PoolConfirmed
+	Height uint64
+}
+
+// Amount возвращает сумму выходов события
+func (e PoolEvent) Amount() util.Amount {
+	var total util.Amount
+	for _, out := range e.Outputs {
+		total += out.Amount
+	}
+	return total
+}
+
+type poolTx struct {
+	// owned - выходы по кошелькам; пусто для чужих транзакций
+	owned       map[string][]types.OwnedOutput
+	doubleSpend bool
+}
+
+// PoolWatcher опрашивает пул демона и сообщает о транзакциях с выходами
+// отслеживаемых кошельков: появление, подтверждение, вытеснение и двойная трата.
+type PoolWatcher struct {
+	mu sync.Mutex
+
+	daemon  PoolDaemon
+	wallets map[string]*util.SubaddressTable
+	logf    types.LogFunc
+
+	// known - транзакции, которые сейчас в пуле (чужие тоже, чтобы не запрашивать их повторно)
+	known map[types.Hash]*poolTx
+}
+
+func NewPoolWatcher(daemon PoolDaemon) *PoolWatcher {
+	return &PoolWatcher{
+		daemon:  daemon,
+		wallets: make(map[string]*util.SubaddressTable),
+		known:   make(map[types.Hash]*poolTx),
+	}
+}
+
+// AddWallet добавляет кошелёк по таблице субадресов (нужен только ключ просмотра).
+// Транзакции, уже просмотренные в пуле, для нового кошелька не пересканируются.
+func (w *PoolWatcher) AddWallet(name string, table *util.SubaddressTable) {
+	w.mu.Lock()
+	w.wallets[name] = table
+	w.mu.Unlock()
+}
+
+func (w *PoolWatcher) RemoveWallet(name string) {
+	w.mu.Lock()
+	delete(w.wallets, name)
+	w.mu.Unlock()
+}
+
+func (w *PoolWatcher) SetLogger(logf types.LogFunc) {
+	w.mu.Lock()
+	w.logf = logf
+	w.mu.Unlock()
+}
+
+// Run опрашивает пул с интервалом interval до отмены ctx и передаёт события в handler.
+// Ошибки опроса передаются в логгер, опрос продолжается.
+func (w *PoolWatcher) Run(ctx context.Context, interval time.Duration, handler func(PoolEvent)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			w.mu.Lock()
+			w.printf("pool poll: %v", err)
+			w.mu.Unlock()
+		}
+		for _, event := range events {
+			handler(event)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll выполняет один опрос пула и возвращает новые события
+func (w *PoolWatcher) Poll(ctx context.Context) ([]PoolEvent, error) {
+	hashes, err := w.daemon.GetTransactionPoolHashesCtx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get pool hashes: %w", err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	inPool := make(map[types.Hash]bool, len(hashes))
+	fetch := []string{}
+	for _, hash := range hashes {
+		inPool[hash] = true
+		tx, ok := w.known[hash]
+		// Свои транзакции перезапрашиваются, чтобы заметить флаг double_spend_seen
+		if !ok || (len(tx.owned) != 0 && !tx.doubleSpend) {
+			fetch = append(fetch, hex.EncodeToString(hash[:]))
+		}
+	}
+	for hash, tx := range w.known {
+		if !inPool[hash] && len(tx.owned) != 0 {
+			fetch = append(fetch, hex.EncodeToString(hash[:]))
+		}
+	}
+
+	entries := map[types.Hash]types.TxEntry{}
+	if len(fetch) != 0 {
+		txs, err := w.daemon.GetTransactionsCtx(ctx, fetch)
+		if err != nil {
+			return nil, fmt.Errorf("get pool transactions: %w", err)
+		}
+		for _, entry := range txs {
+			entries[entry.Hash] = entry
+		}
+	}
+
+	events := []PoolEvent{}
+	for _, hash := range hashes {
+		entry, fetched := entries[hash]
+		tx, ok := w.known[hash]
+		if !ok {
+			if !fetched {
+				// Транзакция ушла из пула между запросами - увидим её в блоке
+				continue
+			}
+			tx = w.scan(entry)
+			w.known[hash] = tx
+			events = append(events, tx.events(PoolSeen, hash, 0)...)
+		}
+
+		if fetched && entry.DoubleSpendSeen && !tx.doubleSpend {
+			tx.doubleSpend = true
+			events = append(events, tx.events(PoolDoubleSpend, hash, 0)...)
+		}
+	}
+
+	for hash, tx := range w.known {
+		if inPool[hash] {
+			continue
+		}
+		delete(w.known, hash)
+
+		entry, fetched := entries[hash]
+		switch {
+		case len(tx.owned) == 0:
+		case fetched && !entry.InPool && entry.BlockHeight != 0:
+			events = append(events, tx.events(PoolConfirmed, hash, entry.BlockHeight)...)
+		case fetched && entry.InPool:
+			// Демон ещё держит транзакцию (например, не ретранслирует её) - следим дальше
+			w.known[hash] = tx
+		default:
+			events = append(events, tx.events(PoolEvicted, hash, 0)...)
+		}
+	}
+	return events, nil
+}
+
+// scan ищет выходы всех кошельков в транзакции пула
+func (w *PoolWatcher) scan(entry types.TxEntry) *poolTx {
+	result := &poolTx{owned: map[string][]types.OwnedOutput{}}
+
+	tx := &types.Transaction{Raw: entry.Blob}
+	if err := tx.ParseTx(); err != nil {
+		w.printf("pool tx %x skipped: %v", entry.Hash, err)
+		return result
+	}
+	tx.ParseRctSig()
+	tx.Hash = entry.Hash
+
+	for name, table := range w.wallets {
+		owned, _, err := tx.ScanOutputs(table, w.logf)
+		if err != nil {
+			w.printf("pool tx %x: %v", entry.Hash, err)
+			continue
+		}
+		if len(owned) != 0 {
+			result.owned[name] = owned
+		}
+	}
+	return result
+}
+
+func (tx *poolTx) events(kind PoolEventKind, hash types.Hash, height uint64) []PoolEvent {
+	events := []PoolEvent{}
+	for name, outputs := range tx.owned {
+		events = append(events, PoolEvent{
+			Kind:    kind,
+			Wallet:  name,
+			TxHash:  hash,
+			Outputs: outputs,
+			Height:  height,
+		})
+	}
+	return events
+}
+
+func (w *PoolWatcher) printf(format string, args ...interface{}) {
+	if w.logf != nil {
+		w.logf(format, args...)
+	}
+}
@@ -0,0 +1,579 @@
+package wallet
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/0xAF4/go-monero/rpc"
+	"github.com/0xAF4/go-monero/types"
+	"github.com/0xAF4/go-monero/util"
+)
+
+// DefaultLookahead - сколько субадресов аккаунта 0 сканер проверяет по умолчанию
+const DefaultLookahead = 50
+
+// Daemon - методы демона, нужные сканеру
+type Daemon interface {
+	GetBlocksFastCtx(ctx context.Context, req types.BlocksRequest) (*types.BlocksResult, error)
+	GetHeightCtx(ctx context.Context) (string, uint64, error)
+	Network() *util.NetworkParams
+}
+
+var _ Daemon = (*rpc.Client)(nil)
+
+// Rollback - событие отката цепочки: блоки с высоты Height и выше заменены,
+// общий предок - Height-1
+type Rollback struct {
+	Height uint64
+	// Detached - id отсоединённых блоков от старых к новым
+	Detached []types.Hash
+	// Removed - выходы из отсоединённых блоков, Unspent - выходы, потраченные в них
+	Removed []Output
+	Unspent []Output
+}
+
+type outpoint struct {
+	txHash types.Hash
+	index  uint64
+}
+
+// Scanner проходит блоки начиная с высоты восстановления, собирает выходы кошелька
+// и отмечает потраченные по образам ключей во входах последующих транзакций.
+// Без ключа траты (view-only) образы ключей не вычисляются и траты не отслеживаются.
+type Scanner struct {
+	mu sync.RWMutex
+
+	daemon Daemon
+	keys   util.WalletKeys
+	table  *util.SubaddressTable
+	chain  *ChainTracker
+	store  Store
+	logf   types.LogFunc
+
+	onRollback func(Rollback)
+
+	// next - следующая высота для сканирования, chainHeight - высота цепочки демона
+	next        uint64
+	chainHeight uint64
+
+	outputs   []*Output
+	byOut     map[outpoint]*Output
+	keyImages map[types.Hash]*Output
+}
+
+// NewScanner создаёт сканер для ключей keys. Нулевой PrivateSpendKey означает view-only кошелёк.
+func NewScanner(daemon Daemon, keys util.WalletKeys, restoreHeight uint64) *Scanner {
+	table := util.NewSubaddressTable(keys.PrivateViewKey, keys.PublicSpendKey)
+	table.AddRange(0, DefaultLookahead)
+	if daemon != nil {
+		table.SetNetwork(daemon.Network().Network)
+	}
+
+	return &Scanner{
+		daemon:    daemon,
+		keys:      keys,
+		table:     table,
+		chain:     NewChainTracker(DefaultChainWindow),
+		next:      restoreHeight,
+		byOut:     make(map[outpoint]*Output),
+		keyImages: make(map[types.Hash]*Output),
+	}
+}
+
+// Table возвращает таблицу субадресов; новые субадреса можно добавлять до сканирования
+func (s *Scanner) Table() *util.SubaddressTable {
+	return s.table
+}
+
+func (s *Scanner) SetLogger(logf types.LogFunc) {
+	s.mu.Lock()
+	s.logf = logf
+	s.mu.Unlock()
+}
+
+// SetRollbackHandler задаёт обработчик откатов; вызывается после отката, вне блокировки сканера
+func (s *Scanner) SetRollbackHandler(handler func(Rollback)) {
+	s.mu.Lock()
+	s.onRollback = handler
+	s.mu.Unlock()
+}
+
+// ViewOnly сообщает, что ключ траты неизвестен
+func (s *Scanner) ViewOnly() bool {
+	return s.keys.PrivateSpendKey == util.Zero
+}
+
+// Height возвращает следующую высоту для сканирования
+func (s *Scanner) Height() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.next
+}
+
+// ChainHeight возвращает последнюю известную высоту цепочки (количество блоков)
+func (s *Scanner) ChainHeight() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.chainHeight
+}
+
+// Sync сканирует блоки до вершины цепочки демона. Демону передаётся разреженная история
+// известных блоков, и он отвечает начиная с общего предка; блоки выше него откатываются.
+func (s *Scanner) Sync(ctx context.Context) error {
+	genesis := types.Hash(s.daemon.Network().GenesisHashBytes())
+
+	for {
+		topHash, height, err := s.daemon.GetHeightCtx(ctx)
+		if err != nil {
+			return fmt.Errorf("get height: %w", err)
+		}
+		s.setChainHeight(height)
+
+		req, synced := s.blocksRequest(genesis, height, topHash)
+		if synced {
+			return nil
+		}
+
+		res, err := s.daemon.GetBlocksFastCtx(ctx, req)
+		if err != nil {
+			return fmt.Errorf("get blocks from %d: %w", req.StartHeight, err)
+		}
+		if len(res.Blocks) == 0 {
+			return nil
+		}
+
+		err = s.ScanBlocks(res.Blocks)
+		if errors.Is(err, ErrBlockNotLinked) {
+			// Вершина отсоединена, следующий запрос найдёт общего предка
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		// Меньшая current_height - не reorg: демон отстаёт, блоки уже сверены по id
+		s.setChainHeight(res.CurrentHeight)
+
+		if err := s.save(); err != nil {
+			return fmt.Errorf("save wallet state: %w", err)
+		}
+		if res.CurrentHeight <= s.Height() {
+			return nil
+		}
+	}
+}
+
+// blocksRequest возвращает запрос следующей порции или synced, если вершина совпадает с демоном
+func (s *Scanner) blocksRequest(genesis types.Hash, height uint64, topHash string) (types.BlocksRequest, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	top, topId, ok := s.chain.Top()
+	if !ok {
+		return types.BlocksRequest{
+			BlockIds:    []types.Hash{genesis},
+			StartHeight: s.next,
+			Prune:       true,
+		}, s.next >= height
+	}
+
+	if top+1 == height && (topHash == "" || topHash == hex.EncodeToString(topId[:])) {
+		return types.BlocksRequest{}, true
+	}
+	// Демон отстаёт, но его вершина есть в нашей цепочке - ждём его
+	if height <= top && height != 0 {
+		if id, ok := s.chain.Id(height - 1); ok && (topHash == "" || topHash == hex.EncodeToString(id[:])) {
+			return types.BlocksRequest{}, true
+		}
+	}
+	return types.BlocksRequest{
+		BlockIds: s.chain.BlockIds(genesis),
+		Prune:    true,
+	}, false
+}
+
+// setChainHeight задаёт высоту по ответу демона; после отката она может уменьшиться
+func (s *Scanner) setChainHeight(height uint64) {
+	s.mu.Lock()
+	s.chainHeight = max(height, s.next)
+	s.mu.Unlock()
+}
+
+// ScanBlocks обрабатывает блоки по порядку. Уже отсканированные блоки пропускаются;
+// блок на известной высоте с другим id откатывает эту высоту и всё выше.
+func (s *Scanner) ScanBlocks(blocks []types.BlockEntry) error {
+	s.mu.Lock()
+	rollbacks, err := s.scanBlocks(blocks)
+	handler := s.onRollback
+	s.mu.Unlock()
+
+	if handler != nil {
+		for _, rollback := range rollbacks {
+			handler(rollback)
+		}
+	}
+	return err
+}
+
+func (s *Scanner) scanBlocks(blocks []types.BlockEntry) ([]Rollback, error) {
+	rollbacks := []Rollback{}
+	for _, entry := range blocks {
+		if entry.Height > s.next {
+			return rollbacks, fmt.Errorf("unexpected block height %d, expected %d", entry.Height, s.next)
+		}
+
+		id := blockId(entry.Block)
+		if known, ok := s.chain.Id(entry.Height); ok && known == id {
+			continue
+		}
+
+		detached, err := s.chain.Connect(entry.Height, id, entry.Block.PreviousBlockHash)
+		if len(detached) != 0 {
+			rollback := s.rollback(s.next - uint64(len(detached)))
+			rollback.Detached = detached
+			rollbacks = append(rollbacks, rollback)
+		}
+		if err != nil {
+			return rollbacks, fmt.Errorf("block %d: %w", entry.Height, err)
+		}
+		if entry.Height != s.next {
+			return rollbacks, fmt.Errorf("block %d is below scanned height %d and not tracked", entry.Height, s.next)
+		}
+
+		if err := s.scanBlock(entry); err != nil {
+			s.chain.Detach(entry.Height)
+			return rollbacks, fmt.Errorf("block %d: %w", entry.Height, err)
+		}
+
+		s.next = entry.Height + 1
+		if s.next > s.chainHeight {
+			s.chainHeight = s.next
+		}
+	}
+	return rollbacks, nil
+}
+
+// rollback удаляет выходы из блоков с высоты height и выше и снимает отметки о тратах в них
+func (s *Scanner) rollback(height uint64) Rollback {
+	rollback := Rollback{Height: height}
+
+	kept := s.outputs[:0]
+	for _, out := range s.outputs {
+		if out.BlockHeight >= height {
+			rollback.Removed = append(rollback.Removed, *out)
+			delete(s.byOut, outpoint{out.TxHash, out.Index})
+			if out.HasKeyImage {
+				delete(s.keyImages, out.KeyImage)
+			}
+			continue
+		}
+
+		if out.Spent && out.SpentHeight >= height {
+			out.Spent = false
+			out.SpentHeight = 0
+			out.SpentTxHash = types.Hash{}
+			rollback.Unspent = append(rollback.Unspent, *out)
+		}
+		kept = append(kept, out)
+	}
+	clear(s.outputs[len(kept):])
+	s.outputs = kept
+
+	if height < s.next {
+		s.next = height
+	}
+	s.printf("rollback to %d: %d outputs removed, %d unspent", height, len(rollback.Removed), len(rollback.Unspent))
+	return rollback
+}
+
+func (s *Scanner) scanBlock(entry types.BlockEntry) error {
+	block := entry.Block
+
+	// Глобальные индексы: [0] - miner tx, если он есть в ответе
+	offset := len(entry.OutputIndices) - len(block.TXs)
+	if len(entry.OutputIndices) != 0 && offset != 0 && offset != 1 {
+		return fmt.Errorf("output indices count %d does not match %d txs", len(entry.OutputIndices), len(block.TXs))
+	}
+	indicesFor := func(i int) []uint64 {
+		if i+offset < 0 || i+offset >= len(entry.OutputIndices) {
+			return nil
+		}
+		return entry.OutputIndices[i+offset]
+	}
+
+	if offset == 1 || len(entry.OutputIndices) == 0 {
+		minerTx := &types.Transaction{
+			UnlockTime: block.MinerTx.UnlockTime,
+			Outputs:    block.MinerTx.Outs,
+			Extra:      block.MinerTx.Extra,
+		}
+		copy(minerTx.Hash[:], block.CalculateMinerTxHash())
+		if err := s.scanTx(minerTx, entry.Height, indicesFor(-1), true); err != nil {
+			return err
+		}
+	}
+
+	for i, blockTx := range block.TXs {
+		tx := &types.Transaction{Raw: blockTx.Raw}
+		if err := tx.ParseTx(); err != nil {
+			s.printf("tx %x at %d skipped: %v", blockTx.Hash, entry.Height, err)
+			continue
+		}
+		tx.ParseRctSig()
+		tx.Hash = blockTx.Hash
+
+		if err := s.scanTx(tx, entry.Height, indicesFor(i), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) scanTx(tx *types.Transaction, height uint64, indices []uint64, coinbase bool) error {
+	for _, in := range tx.Inputs {
+		if in.Type != 0x02 {
+			continue
+		}
+		if out, ok := s.keyImages[in.KeyImage]; ok && !out.Spent {
+			out.Spent = true
+			out.SpentHeight = height
+			out.SpentTxHash = tx.Hash
+			s.printf("output %x:%d spent in %x at %d", out.TxHash, out.Index, tx.Hash, height)
+		}
+	}
+
+	if len(tx.Outputs) == 0 {
+		return nil
+	}
+
+	owned, _, err := tx.ScanOutputs(s.table, s.logf)
+	if err != nil {
+		// Чужая транзакция с некорректным extra не должна останавливать синхронизацию
+		s.printf("tx %x: %v", tx.Hash, err)
+		return nil
+	}
+
+	for _, o := range owned {
+		key := outpoint{o.TxHash, o.Index}
+		if _, ok := s.byOut[key]; ok {
+			continue
+		}
+		if o.Index >= uint64(len(indices)) {
+			return fmt.Errorf("tx %x: missing global index for output %d", tx.Hash, o.Index)
+		}
+
+		o.BlockHeight = height
+		out := &Output{
+			OwnedOutput: o,
+			GlobalIndex: indices[o.Index],
+			Coinbase:    coinbase,
+		}
+
+		if !s.ViewOnly() {
+			keyImage, err := s.keyImage(o)
+			if err != nil {
+				return fmt.Errorf("tx %x: output %d: %w", tx.Hash, o.Index, err)
+			}
+			if prev, ok := s.keyImages[keyImage]; ok {
+				// Повторный образ ключа (burning bug): второй выход потратить нельзя
+				s.printf("tx %x: output %d duplicates key image of %x:%d, skipped", tx.Hash, o.Index, prev.TxHash, prev.Index)
+				continue
+			}
+			out.KeyImage = keyImage
+			out.HasKeyImage = true
+			s.keyImages[keyImage] = out
+		}
+
+		s.outputs = append(s.outputs, out)
+		s.byOut[key] = out
+	}
+	return nil
+}
+
+// keyImage вычисляет образ ключа выхода; секретный ключ траты субадреса - b + m
+func (s *Scanner) keyImage(o types.OwnedOutput) (types.Hash, error) {
+	spendSecret := s.keys.PrivateSpendKey
+	if !o.Subaddress.IsPrimary() {
+		m := util.SubaddressSecretKey(&s.keys.PrivateViewKey, o.Subaddress)
+		util.ScAdd(&spendSecret, &s.keys.PrivateSpendKey, &m)
+	}
+	pubSpend := util.SubaddressSpendKey(&s.keys.PrivateViewKey, &s.keys.PublicSpendKey, o.Subaddress)
+	txPubKey := util.Key(o.TxPubKey)
+
+	keyImage, _, err := util.CreateKeyImage(&pubSpend, &spendSecret, &s.keys.PrivateViewKey, &txPubKey, o.Index)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return types.Hash(*keyImage), nil
+}
+
+func (s *Scanner) printf(format string, args ...interface{}) {
+	if s.logf != nil {
+		s.logf(format, args...)
+	}
+}
+
+// Outputs возвращает копии всех найденных выходов, включая потраченные
+func (s *Scanner) Outputs() []Output {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]Output, 0, len(s.outputs))
+	for _, out := range s.outputs {
+		result = append(result, *out)
+	}
+	return result
+}
+
+// SpendableOutputs возвращает непотраченные разблокированные выходы для TxBuilder.AddInput
+func (s *Scanner) SpendableOutputs(now time.Time) []types.OwnedOutput {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []types.OwnedOutput{}
+	for _, out := range s.outputs {
+		if !out.Spent && out.IsUnlocked(s.chainHeight, now) {
+			result = append(result, out.OwnedOutput)
+		}
+	}
+	return result
+}
+
+// Balance возвращает баланс непотраченных выходов на момент now
+func (s *Scanner) Balance(now time.Time) Balance {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	balance := Balance{}
+	for _, out := range s.outputs {
+		if !out.Spent {
+			balance.add(out, s.chainHeight, now)
+		}
+	}
+	return balance
+}
+
+// History возвращает историю транзакций кошелька по высоте, восстановленную из выходов
+func (s *Scanner) History() []Transfer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byTx := map[types.Hash]*Transfer{}
+	record := func(hash types.Hash, height uint64) *Transfer {
+		if t, ok := byTx[hash]; ok {
+			return t
+		}
+		t := &Transfer{TxHash: hash, Height: height}
+		byTx[hash] = t
+		return t
+	}
+
+	for _, out := range s.outputs {
+		record(out.TxHash, out.BlockHeight).Received += out.Amount
+		if out.Spent {
+			record(out.SpentTxHash, out.SpentHeight).Spent += out.Amount
+		}
+	}
+
+	history := make([]Transfer, 0, len(byTx))
+	for _, t := range byTx {
+		history = append(history, *t)
+	}
+	slices.SortFunc(history, func(a, b Transfer) int {
+		if c := cmp.Compare(a.Height, b.Height); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.TxHash[:], b.TxHash[:])
+	})
+	return history
+}
+
+// AttachStore восстанавливает сохранённое в store состояние, если оно есть;
+// после этого Sync сохраняет состояние после каждой порции блоков
+func (s *Scanner) AttachStore(store Store) error {
+	state, err := store.Load()
+	if err != nil && !errors.Is(err, ErrNoState) {
+		return err
+	}
+	if state != nil {
+		if err := s.Restore(state); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.store = store
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Scanner) save() error {
+	s.mu.RLock()
+	store := s.store
+	s.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+	return store.Save(s.State())
+}
+
+// State возвращает снимок состояния для Store.Save
+func (s *Scanner) State() *State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := &State{
+		Version:        stateVersion,
+		PublicSpendKey: types.Hash(s.keys.PublicSpendKey),
+		PublicViewKey:  types.Hash(s.keys.PublicViewKey),
+		Height:         s.next,
+		ChainHeight:    s.chainHeight,
+		ChainBase:      s.chain.Base(),
+		BlockIds:       append([]types.Hash{}, s.chain.ids...),
+		Outputs:        make([]Output, 0, len(s.outputs)),
+	}
+	for _, out := range s.outputs {
+		state.Outputs = append(state.Outputs, *out)
+	}
+	return state
+}
+
+// Restore заменяет состояние сканера сохранённым; состояние должно принадлежать тем же ключам
+func (s *Scanner) Restore(state *State) error {
+	if state.Version != stateVersion {
+		return fmt.Errorf("unsupported wallet state version %d", state.Version)
+	}
+	if state.PublicSpendKey != types.Hash(s.keys.PublicSpendKey) || state.PublicViewKey != types.Hash(s.keys.PublicViewKey) {
+		return fmt.Errorf("wallet state belongs to other keys")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next = state.Height
+	s.chainHeight = state.ChainHeight
+	s.chain.restore(state.ChainBase, state.BlockIds)
+
+	s.outputs = s.outputs[:0]
+	s.byOut = make(map[outpoint]*Output, len(state.Outputs))
+	s.keyImages = make(map[types.Hash]*Output, len(state.Outputs))
+	for _, saved := range state.Outputs {
+		out := saved
+		s.outputs = append(s.outputs, &out)
+		s.byOut[outpoint{out.TxHash, out.Index}] = &out
+		if out.HasKeyImage {
+			s.keyImages[out.KeyImage] = &out
+		}
+		// Выходы на субадреса за пределами lookahead должны находиться и после перезапуска
+		s.table.Add(out.Subaddress)
+	}
+	return nil
+}
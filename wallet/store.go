@@ -0,0 +1,215 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/0xAF4/go-monero/types"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const stateVersion = 1
+
+var (
+	// ErrNoState - в хранилище ещё нет сохранённого состояния
+	ErrNoState = errors.New("wallet state not found")
+	// ErrWrongPassword - файл не расшифровывается паролем (или повреждён)
+	ErrWrongPassword = errors.New("wrong password or corrupted wallet file")
+)
+
+// State - сохраняемое состояние сканера
+type State struct {
+	Version int `json:"version"`
+	// PublicSpendKey/PublicViewKey - ключи кошелька, которому принадлежит состояние
+	PublicSpendKey types.Hash `json:"public_spend_key"`
+	PublicViewKey  types.Hash `json:"public_view_key"`
+
+	// Height - следующая высота для сканирования
+	Height      uint64 `json:"height"`
+	ChainHeight uint64 `json:"chain_height"`
+	// ChainBase - высота BlockIds[0]; BlockIds - окно ChainTracker
+	ChainBase uint64       `json:"chain_base"`
+	BlockIds  []types.Hash `json:"block_ids"`
+
+	// Outputs - все выходы, включая потраченные; образы ключей и история транзакций
+	// восстанавливаются из них
+	Outputs []Output `json:"outputs"`
+}
+
+// Store - хранилище состояния кошелька
+type Store interface {
+	// Load возвращает сохранённое состояние или ErrNoState
+	Load() (*State, error)
+	Save(state *State) error
+}
+
+// MemoryStore хранит копию состояния в памяти
+type MemoryStore struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (m *MemoryStore) Load() (*State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data == nil {
+		return nil, ErrNoState
+	}
+	state := &State{}
+	if err := json.Unmarshal(m.data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (m *MemoryStore) Save(state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.data = data
+	m.mu.Unlock()
+	return nil
+}
+
+// Формат файла: magic | salt | nonce | XChaCha20-Poly1305(JSON состояния).
+// Ключ - argon2id(password, salt); magic и salt входят в associated data.
+var fileMagic = []byte("GMWALLET\x01")
+
+const (
+	fileSaltSize = 16
+
+	argonTime    = 1
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+)
+
+// FileStore хранит состояние в одном зашифрованном файле. Запись атомарна:
+// временный файл в том же каталоге переименовывается поверх старого.
+type FileStore struct {
+	mu       sync.Mutex
+	path     string
+	password []byte
+
+	// salt и key кэшируются, чтобы не выводить ключ argon2id при каждом сохранении
+	salt []byte
+	key  []byte
+}
+
+func NewFileStore(path string, password []byte) *FileStore {
+	return &FileStore{
+		path:     path,
+		password: bytes.Clone(password),
+	}
+}
+
+func (f *FileStore) deriveKey(salt []byte) []byte {
+	if f.key == nil || !bytes.Equal(f.salt, salt) {
+		f.salt = bytes.Clone(salt)
+		f.key = argon2.IDKey(f.password, salt, argonTime, argonMemory, argonThreads, chacha20poly1305.KeySize)
+	}
+	return f.key
+}
+
+func (f *FileStore) Load() (*State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoState
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header := len(fileMagic) + fileSaltSize
+	if len(data) < header+chacha20poly1305.NonceSizeX || !bytes.Equal(data[:len(fileMagic)], fileMagic) {
+		return nil, fmt.Errorf("%s: not a wallet file", f.path)
+	}
+
+	aead, err := chacha20poly1305.NewX(f.deriveKey(data[len(fileMagic):header]))
+	if err != nil {
+		return nil, err
+	}
+	nonce := data[header : header+aead.NonceSize()]
+	plain, err := aead.Open(nil, nonce, data[header+aead.NonceSize():], data[:header])
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(plain, state); err != nil {
+		return nil, fmt.Errorf("decode wallet state: %w", err)
+	}
+	return state, nil
+}
+
+func (f *FileStore) Save(state *State) error {
+	plain, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	salt := f.salt
+	if salt == nil {
+		salt = make([]byte, fileSaltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+	}
+
+	aead, err := chacha20poly1305.NewX(f.deriveKey(salt))
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	data := append(bytes.Clone(fileMagic), salt...)
+	data = append(data, nonce...)
+	data = aead.Seal(data, nonce, plain, data[:len(fileMagic)+fileSaltSize])
+
+	return writeFileAtomic(f.path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}